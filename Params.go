@@ -36,6 +36,7 @@ const (
 	FromOther
 	FromDefault
 	FromAuth
+	FromHost
 )
 
 const MaxParams = 200
@@ -63,10 +64,28 @@ type Params struct {
 
 func InitParams(p *Params) {
 	p.Data = p.allParam[:]
-	p.search = make(map[string]int)
+	if p.search == nil {
+		p.search = make(map[string]int)
+	} else if len(p.search) > 0 {
+		for k := range p.search { // Re-use the map when the Params is re-used (pooled)
+			delete(p.search, k)
+		}
+	}
+	p.search_ready = false
+	p.route_i = 0
 	p.NParam = 0
 }
 
+// Copy returns Params that do not share storage with ps.  The Params passed to a handler
+// are re-used after the handler returns, so use this to keep them.
+func (ps *Params) Copy() (rv Params) {
+	InitParams(&rv)
+	rv.NParam = ps.NParam
+	rv.route_i = ps.route_i
+	copy(rv.Data, ps.Data[0:ps.NParam])
+	return
+}
+
 func FromTypeToString(ff FromType) string {
 	switch ff {
 	case FromURL:
@@ -89,6 +108,8 @@ func FromTypeToString(ff FromType) string {
 		return "FromDefault"
 	case FromAuth:
 		return "FromAuth"
+	case FromHost:
+		return "FromHost"
 	default:
 		return "Unk-FromType"
 	}
@@ -114,12 +135,16 @@ func (ff ParamType) String() string {
 		return "-cookie-"
 	case 'e':
 		return "-encrypted-"
+	case 'h':
+		return "-host-"
+	case 'H':
+		return "-header-"
 	}
 	return fmt.Sprintf("p?:%s", string(rune(ff)))
 }
 
 func (ps *Params) MakeStringMap(mdata map[string]string) {
-	for _, v := range ps.Data {
+	for _, v := range ps.Data[0:ps.NParam] {
 		mdata[v.Name] = v.Value
 	}
 }
@@ -130,7 +155,7 @@ func (ps *Params) CreateSearch() {
 		return
 	}
 
-	for i, v := range ps.Data {
+	for i, v := range ps.Data[0:ps.NParam] {
 		ps.search[v.Name] = i
 	}
 	// fmt.Printf("CreateSearch - set to true\n")
@@ -36,22 +36,23 @@ var testSplitSlash3_Data = []struct {
 
 func TestSplitOnSlash3a(t *testing.T) {
 	htx := NewRouter()
+	ms := NewMatchState()
 	// fmt.Printf("This ONe\n")
 	for i, test := range testSplitSlash3_Data {
-		htx.SplitOnSlash3(1, test.Url, false)
-		// fmt.Printf("NSl = %d: ->%s<- Slash %s, %s\n", htx.NSl, htx.CurUrl, debug.SVar(htx.Slash[0:htx.NSl+1]), debug.LF())
-		rv := debug.SVar(htx.Slash[0 : htx.NSl+1])
+		htx.SplitOnSlash3(ms, 1, test.Url, false)
+		// fmt.Printf("NSl = %d: ->%s<- Slash %s, %s\n", ms.NSl, ms.CurUrl, debug.SVar(ms.Slash[0:ms.NSl+1]), debug.LF())
+		rv := debug.SVar(ms.Slash[0 : ms.NSl+1])
 		if rv != test.Result {
 			t.Errorf("SplitOnSlash3 [%d] URL:%s failed, Expected ->%s<- got ->%s<-\n", i, test.Url, test.Result, rv)
 		}
-		if htx.CurUrl != test.NewUrl {
-			t.Errorf("SplitOnSlash3 [%d] URL:%s failed, Expected ->%s<- got ->%s<-\n", i, test.Url, test.NewUrl, htx.CurUrl)
+		if ms.CurUrl != test.NewUrl {
+			t.Errorf("SplitOnSlash3 [%d] URL:%s failed, Expected ->%s<- got ->%s<-\n", i, test.Url, test.NewUrl, ms.CurUrl)
 		}
 	}
 	for i, test := range testSplitSlash3_Data {
-		htx.SplitOnSlash3(1, test.Url, true)
-		// fmt.Printf("NSl = %d: ->%s<- Slash %s, %s\n", htx.NSl, htx.CurUrl, debug.SVar(htx.Slash[0:htx.NSl+1]), debug.LF())
-		rv := debug.SVar(htx.Slash[0 : htx.NSl+1])
+		htx.SplitOnSlash3(ms, 1, test.Url, true)
+		// fmt.Printf("NSl = %d: ->%s<- Slash %s, %s\n", ms.NSl, ms.CurUrl, debug.SVar(ms.Slash[0:ms.NSl+1]), debug.LF())
+		rv := debug.SVar(ms.Slash[0 : ms.NSl+1])
 		if rv != test.ResultEarlyExit {
 			t.Errorf("SplitOnSlash3 [%d] URL:%s failed, Expected ->%s<- got ->%s<-\n", i, test.Url, test.ResultEarlyExit, rv)
 		}
@@ -63,10 +64,11 @@ func TestSplitOnSlash3a(t *testing.T) {
 // 29.2 ns - early exit - no /repos in hash table.
 func OldBenchmarkOfSplitOnSlash3_long(b *testing.B) {
 	htx := NewRouter()
+	ms := NewMatchState()
 
 	url := "/repos/julienschmidt/httprouter/stargazers"
 	for n := 0; n < b.N; n++ {
-		htx.SplitOnSlash3(1, url, true)
+		htx.SplitOnSlash3(ms, 1, url, true)
 	}
 }
 
@@ -75,10 +77,11 @@ func OldBenchmarkOfSplitOnSlash3_long(b *testing.B) {
 // 28.4 ns - early exit vesion for (index.html)
 func OldBenchmarkOfSplitOnSlash3_short(b *testing.B) {
 	htx := NewRouter()
+	ms := NewMatchState()
 
 	url := "/repos"
 	url = "/index.html"
 	for n := 0; n < b.N; n++ {
-		htx.SplitOnSlash3(1, url, true)
+		htx.SplitOnSlash3(ms, 1, url, true)
 	}
 }
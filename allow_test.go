@@ -0,0 +1,159 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_MethodNotAllowed(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/items/:id", createFx(9001)).Methods("GET", "PUT")
+	r.HandleFunc("/items/:id", createFx(9002)).Methods("DELETE")
+	r.HandleFunc("/other", createFx(9003)).Methods("POST")
+
+	// Not set, so NotFound
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("POST", "/items/12"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without MethodNotAllowed, got %d\n", rec.Code)
+	}
+
+	r.MethodNotAllowed = NotAllowed
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("POST", "/items/12"))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d\n", rec.Code)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "DELETE, GET, PUT" {
+		t.Errorf("Expected Allow: DELETE, GET, PUT, got ->%s<-\n", allow)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/nothing/here"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a path with no routes, got %d\n", rec.Code)
+	}
+
+	arrived = 0
+	r.ServeHTTP(httptest.NewRecorder(), newTestRequest("PUT", "/items/12"))
+	if arrived != 9001 {
+		t.Errorf("Expected 9001, got %d\n", arrived)
+	}
+}
+
+func Test_AutoOPTIONS(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/items/:id", createFx(9011)).Methods("GET", "PUT")
+	r.HandleFunc("/users/:id", createFx(9012)).Methods("GET")
+	r.HandleFunc("/users/:id", createFx(9013)).Methods("OPTIONS")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("OPTIONS", "/items/12"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without HandleOPTIONS, got %d\n", rec.Code)
+	}
+
+	r.HandleOPTIONS = true
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("OPTIONS", "/items/12"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d\n", rec.Code)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "GET, PUT, OPTIONS" {
+		t.Errorf("Expected Allow: GET, PUT, OPTIONS, got ->%s<-\n", allow)
+	}
+
+	// User registered OPTIONS route wins.
+	arrived = 0
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("OPTIONS", "/users/12"))
+	if arrived != 9013 || rec.Header().Get("Allow") != "" {
+		t.Errorf("Expected the registered OPTIONS route 9013, got %d\n", arrived)
+	}
+
+	r.OptionsHandler = func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Access-Control-Allow-Methods", w.Header().Get("Allow"))
+		w.WriteHeader(http.StatusNoContent)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("OPTIONS", "/items/12"))
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Methods") != "GET, PUT, OPTIONS" {
+		t.Errorf("Expected OptionsHandler to be called, got %d %v\n", rec.Code, rec.Header())
+	}
+}
+
+func Test_HandleHEAD(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/page/:id", func(w http.ResponseWriter, req *http.Request, ps Params) {
+		w.Header().Set("X-Id", ps.ByName("id"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("hello world"))
+	}).Methods("GET")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("HEAD", "/page/7"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without HandleHEAD, got %d\n", rec.Code)
+	}
+
+	r.HandleHEAD = true
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("HEAD", "/page/7"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d\n", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected the body to be discarded, got ->%s<-\n", rec.Body.String())
+	}
+	if rec.Header().Get("X-Id") != "7" || rec.Header().Get("Content-Length") != "11" {
+		t.Errorf("Expected X-Id: 7 and Content-Length: 11, got %v\n", rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/page/7"))
+	if rec.Body.String() != "hello world" {
+		t.Errorf("Expected the body for GET, got ->%s<-\n", rec.Body.String())
+	}
+
+	for i := 0; i < 4; i++ { // The MatchState from a HEAD is used again by MatchAndServeHTTP
+		r.ServeHTTP(httptest.NewRecorder(), newTestRequest("HEAD", "/page/7"))
+		r.MatchAndServeHTTP(httptest.NewRecorder(), newTestRequest("HEAD", "/page/7"))
+		rec = httptest.NewRecorder()
+		if !r.MatchAndServeHTTP(rec, newTestRequest("GET", "/page/7")) || rec.Body.String() != "hello world" {
+			t.Errorf("Test %d: expected the body for GET through MatchAndServeHTTP, got ->%s<-\n", i, rec.Body.String())
+		}
+	}
+
+	// HEAD is served by the GET route, so it is in the Allow header.
+	r.HandleFunc("/page/:id", createFx(9021)).Methods("PUT")
+	r.HandleOPTIONS = true
+	r.MethodNotAllowed = NotAllowed
+	for _, method := range []string{"DELETE", "OPTIONS"} {
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, newTestRequest(method, "/page/7"))
+		expect := "GET, HEAD, PUT"
+		if method == "OPTIONS" {
+			expect += ", OPTIONS"
+		}
+		if allow := rec.Header().Get("Allow"); allow != expect {
+			t.Errorf("%s: expected Allow: %s, got ->%s<-\n", method, expect, allow)
+		}
+	}
+	r.HandleHEAD = false
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("DELETE", "/page/7"))
+	if allow := rec.Header().Get("Allow"); allow != "GET, PUT" {
+		t.Errorf("Expected Allow: GET, PUT without HandleHEAD, got ->%s<-\n", allow)
+	}
+}
@@ -0,0 +1,118 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_CaseInsensitiveRouter(t *testing.T) {
+	var got string
+	r := NewRouter()
+	r.CaseInsensitive = true
+	r.HandleFunc("/api/GetUser/:name", func(w http.ResponseWriter, req *http.Request, ps Params) {
+		arrived = 9201
+		got = ps.ByName("name")
+	}).Methods("GET")
+	r.HandleFunc("/api/list", createFx(9202)).Methods("GET")
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	var caseTests = []struct {
+		Url     string
+		Arrived int
+		Name    string
+	}{
+		{"/api/GetUser/Bob", 9201, "Bob"},
+		{"/API/getuser/Bob", 9201, "Bob"},
+		{"/Api/GETUSER/mIxEd", 9201, "mIxEd"},
+		{"/API/LIST", 9202, ""},
+		{"/api/lists", -1, ""},
+	}
+	for i, test := range caseTests {
+		arrived, got = 0, ""
+		r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", test.Url))
+		if arrived != test.Arrived || got != test.Name {
+			t.Errorf("Test %d: %s expected %d ->%s<- got %d ->%s<-\n", i, test.Url, test.Arrived, test.Name, arrived, got)
+		}
+	}
+}
+
+func Test_CaseInsensitiveRoute(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/Legacy/:id", createFx(9211)).Methods("GET").CaseInsensitive()
+	r.HandleFunc("/Exact/:id", createFx(9212)).Methods("GET")
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", "/LEGACY/12"))
+	if arrived != 9211 {
+		t.Errorf("Expected 9211 for the case-insensitive route, got %d\n", arrived)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", "/exact/12"))
+	if arrived != -1 {
+		t.Errorf("Expected not found for the case-sensitive route, got %d\n", arrived)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", "/Exact/12"))
+	if arrived != 9212 {
+		t.Errorf("Expected 9212, got %d\n", arrived)
+	}
+
+	r.RedirectCase = true
+	arrived = 0
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/legacy/AbC?x=1"))
+	if arrived != 0 || rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/Legacy/AbC?x=1" {
+		t.Errorf("Expected 301 to /Legacy/AbC?x=1, got %d %s arrived=%d\n", rec.Code, rec.Header().Get("Location"), arrived)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", "/Legacy/AbC"))
+	if arrived != 9211 {
+		t.Errorf("Expected 9211 for the canonical case, got %d\n", arrived)
+	}
+}
+
+func Test_RedirectCasePrefix(t *testing.T) {
+	r := NewRouter()
+	r.CaseInsensitive = true
+	r.RedirectCase = true
+	s := r.PathPrefix("/api/").Subrouter()
+	s.HandleFunc("/Users", createFx(9221)).Methods("GET")
+	r.HandleFunc(`/ns/\:System`, createFx(9222)).Methods("GET")
+	r.HandleFunc("/Files/*path", createFx(9223)).Methods("GET")
+	r.HandleFunc("/Docs/", createFx(9224)).Methods("GET")
+
+	tests := []struct {
+		url      string
+		location string
+		expect   int
+	}{
+		{"/API/users", "/api/Users", 9221},
+		{"/NS/:system", "/ns/:System", 9222},
+		{"/files/A%20b/C", "/Files/A%20b/C", 9223},
+		{"/docs/", "/Docs/", 9224},
+		{"/docs", "/Docs", 9224},
+		{"/api/users/", "/api/Users/", 9221},
+	}
+	for i, test := range tests {
+		arrived = 0
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, newTestRequest("GET", test.url))
+		if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != test.location {
+			t.Errorf("Test %d: %s expected 301 to %s, got %d %s\n", i, test.url, test.location, rec.Code, rec.Header().Get("Location"))
+			continue
+		}
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, newTestRequest("GET", test.location))
+		if arrived != test.expect || rec.Code != http.StatusOK {
+			t.Errorf("Test %d: %s expected %d after the redirect, got %d %d\n", i, test.location, test.expect, rec.Code, arrived)
+		}
+	}
+}
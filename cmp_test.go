@@ -20,16 +20,17 @@ import (
 func Test_CmpUrlToCleanRoute(t *testing.T) {
 
 	r := htx
+	ms := NewMatchState()
 	url := "/abc/:def/ghi"
-	r.SplitOnSlash3(0, url, false)
+	r.SplitOnSlash3(ms, 0, url, false)
 
 	if false {
-		fmt.Printf("r.Slash=%s NSl=%d %s\n", debug.SVar(r.Slash[0:r.NSl+1]), r.NSl, debug.LF())
+		fmt.Printf("ms.Slash=%s NSl=%d %s\n", debug.SVar(ms.Slash[0:ms.NSl+1]), ms.NSl, debug.LF())
 	}
 
-	b := r.CmpUrlToCleanRoute("T:T", "/abc/:/ghi")
+	b := r.CmpUrlToCleanRoute(ms, "T:T", "/abc/:/ghi")
 	if false {
-		fmt.Printf("b=%v\n", b, debug.LF())
+		fmt.Printf("b=%v %s\n", b, debug.LF())
 	}
 	if !b {
 		t.Errorf("Not Found\n")
@@ -40,12 +41,13 @@ func Test_CmpUrlToCleanRoute(t *testing.T) {
 // 36 ns
 func OldBenchmark_CmpUrlToCleanRoute(b *testing.B) {
 	r := htx
+	ms := NewMatchState()
 
 	url := "/abc/:def/ghi"
-	r.SplitOnSlash3(0, url, false)
+	r.SplitOnSlash3(ms, 0, url, false)
 
 	for n := 0; n < b.N; n++ {
-		b := r.CmpUrlToCleanRoute("T:T", "/abc/:/ghi")
+		b := r.CmpUrlToCleanRoute(ms, "T:T", "/abc/:/ghi")
 		_ = b
 	}
 }
@@ -0,0 +1,181 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+// Run with:  go test -race -run Concurrent
+//
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+var concurrentRoutes = []string{
+	"/users/:user/repos/:repo",
+	"/users/:user/events",
+	"/orgs/:org/members/:member",
+	"/rc/{id:^[0-9][0-9]*$}",
+	"/static/*filename",
+	"/emojis",
+}
+
+func newConcurrentRouter(t testing.TB, nBad *int64) *MuxRouter {
+	r := NewRouter()
+	check := func(w http.ResponseWriter, req *http.Request, ps Params) {
+		// The expected values are in the query, "name=value&name=value"
+		q, err := url.ParseQuery(req.URL.RawQuery)
+		if err != nil {
+			atomic.AddInt64(nBad, 1)
+			return
+		}
+		for name, v := range q {
+			if got := ps.ByName(name); got != v[0] {
+				atomic.AddInt64(nBad, 1)
+				t.Errorf("Url %s: param %s expected ->%s<- got ->%s<-", req.URL.Path, name, v[0], got)
+			}
+		}
+	}
+	for _, v := range concurrentRoutes {
+		r.HandleFunc(v, check).Methods("GET")
+	}
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(nBad, 1)
+		t.Errorf("Url %s: not found", req.URL.Path)
+	}
+	return r
+}
+
+func concurrentRequest(g, i int) *http.Request {
+	var path, query string
+	switch i % 5 {
+	case 0:
+		path = fmt.Sprintf("/users/u%d/repos/r%d", g, i)
+		query = fmt.Sprintf("user=u%d&repo=r%d", g, i)
+	case 1:
+		path = fmt.Sprintf("/users/u%d-%d/events", g, i)
+		query = fmt.Sprintf("user=u%d-%d", g, i)
+	case 2:
+		path = fmt.Sprintf("/orgs/o%d/members/m%d", g, i)
+		query = fmt.Sprintf("org=o%d&member=m%d", g, i)
+	case 3:
+		path = fmt.Sprintf("/rc/%d%d", g, i)
+		query = fmt.Sprintf("id=%d%d", g, i)
+	case 4:
+		path = fmt.Sprintf("/static/js/g%d/f%d.js", g, i)
+		query = fmt.Sprintf("filename=js/g%d/f%d.js", g, i)
+	}
+	return &http.Request{
+		Method: "GET",
+		URL:    &url.URL{Path: path, RawQuery: query},
+		Host:   "localhost:8080",
+		Proto:  "HTTP/1.1",
+		Header: make(http.Header),
+	}
+}
+
+// Hammer ServeHTTP from many goroutines.  Each request checks that the params it
+// got are the ones from its own URL.  The race detector will report any shared
+// per-request state.
+func Test_ConcurrentServeHTTP(t *testing.T) {
+	var nBad int64
+	r := newConcurrentRouter(t, &nBad)
+	w := new(mockResponseWriter)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				r.ServeHTTP(w, concurrentRequest(g, i))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if nBad != 0 {
+		t.Errorf("Expected 0 bad matches, got %d", nBad)
+	}
+}
+
+// Concurrent first requests must compile the routes exactly once.
+func Test_ConcurrentCompile(t *testing.T) {
+	var nBad int64
+	r := newConcurrentRouter(t, &nBad)
+	w := new(mockResponseWriter)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			r.ServeHTTP(w, concurrentRequest(g, g))
+		}(g)
+	}
+	wg.Wait()
+
+	if nBad != 0 {
+		t.Errorf("Expected 0 bad matches, got %d", nBad)
+	}
+}
+
+func benchmarkServeHTTP(b *testing.B, path string) {
+	var nBad int64
+	r := NewRouter()
+	fx := func(w http.ResponseWriter, req *http.Request, ps Params) {}
+	for _, v := range concurrentRoutes {
+		r.HandleFunc(v, fx).Methods("GET")
+	}
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { nBad++ }
+	r.CompileRoutes()
+	req := &http.Request{Method: "GET", URL: &url.URL{Path: path}, Header: make(http.Header)}
+	w := new(mockResponseWriter)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		r.ServeHTTP(w, req)
+	}
+	if nBad != 0 {
+		b.Errorf("Expected to find %s", path)
+	}
+}
+
+func BenchmarkServeHTTP_Static(b *testing.B) {
+	benchmarkServeHTTP(b, "/emojis")
+}
+
+func BenchmarkServeHTTP_Github1Param(b *testing.B) {
+	benchmarkServeHTTP(b, "/users/julienschmidt/events")
+}
+
+func BenchmarkServeHTTP_Github2Param(b *testing.B) {
+	benchmarkServeHTTP(b, "/users/julienschmidt/repos/httprouter")
+}
+
+func BenchmarkServeHTTP_Parallel(b *testing.B) {
+	var nBad int64
+	r := newConcurrentRouter(b, &nBad)
+	r.CompileRoutes()
+	req := &http.Request{Method: "GET", URL: &url.URL{Path: "/users/julienschmidt/repos/httprouter"}, Header: make(http.Header)}
+	w := new(mockResponseWriter)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			r.ServeHTTP(w, req)
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ConflictKind is the type of problem found between two routes.
+type ConflictKind int
+
+const (
+	DuplicateRoute  ConflictKind = iota // Same method, path and matchers
+	EquivalentRoute                     // Same method and matchers, the path only differs in the names of the params
+	ShadowedRoute                       // The other route has a subset of the matchers and is tried first
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case DuplicateRoute:
+		return "duplicate"
+	case EquivalentRoute:
+		return "equivalent"
+	case ShadowedRoute:
+		return "shadowed"
+	}
+	return fmt.Sprintf("ConflictKind(%d)", int(k))
+}
+
+// RouteConflict is a route that can not be reached because of another route.  Both
+// are reported with the file and line where they were defined.
+type RouteConflict struct {
+	Kind          ConflictKind
+	Method        string
+	Route         string // The route that can not be reached
+	FileName      string //
+	LineNo        int    //
+	OtherRoute    string // The route that is used instead
+	OtherFileName string //
+	OtherLineNo   int    //
+}
+
+func (c RouteConflict) Error() string {
+	return fmt.Sprintf("%s route %s %s FileName: %s LineNo: %d, conflicts with %s FileName: %s LineNo: %d",
+		c.Kind, c.Method, c.Route, c.FileName, c.LineNo, c.OtherRoute, c.OtherFileName, c.OtherLineNo)
+}
+
+// Conflicts returns the duplicate, equivalent and shadowed routes found when the
+// table was built.
+func (t *RouteTable) Conflicts() []RouteConflict {
+	return t.conflicts
+}
+
+// Find routes that can not be reached.  Routes with a MatcherFunc are not checked, there
+// is no way to compare the functions.  Called after t.routeData is sorted, so for routes
+// with the same method and path the one that is tried first is first.
+func (t *RouteTable) findConflicts(r *MuxRouter) {
+	type entry struct {
+		rd    *RouteData
+		conds map[string]bool
+	}
+	groups := make(map[string][]entry)
+	var keys []string
+	for k := range t.routeData {
+		rd := &t.routeData[k]
+		v := t.routes[rd.NFxNo]
+		if len(v.DMatchers) > 0 {
+			continue
+		}
+		key := rd.Method + " " + r.routeSkeleton(rd.Route, v.DNoCase || r.CaseInsensitive)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], entry{rd: rd, conds: routeConds(v)})
+	}
+	for _, key := range keys {
+		g := groups[key]
+		for j := 1; j < len(g); j++ {
+			for i := 0; i < j; i++ {
+				a, b := g[i], g[j] // a is tried before b
+				kind := ShadowedRoute
+				if len(a.conds) == len(b.conds) && subsetConds(a.conds, b.conds) {
+					kind = EquivalentRoute
+					if a.rd.Route == b.rd.Route {
+						kind = DuplicateRoute
+					}
+					if a.rd.NFxNo > b.rd.NFxNo { // Report the one that was defined last
+						a, b = b, a
+					}
+				} else if len(a.conds) == 0 || !subsetConds(a.conds, b.conds) {
+					continue
+				}
+				used, lost := t.routes[a.rd.NFxNo], t.routes[b.rd.NFxNo]
+				t.conflicts = append(t.conflicts, RouteConflict{Kind: kind, Method: b.rd.Method,
+					Route: b.rd.Route, FileName: lost.FileName, LineNo: lost.LineNo,
+					OtherRoute: a.rd.Route, OtherFileName: used.FileName, OtherLineNo: used.LineNo})
+				break
+			}
+		}
+	}
+	sort.SliceStable(t.conflicts, func(i, j int) bool {
+		return t.conflicts[i].FileName < t.conflicts[j].FileName ||
+			(t.conflicts[i].FileName == t.conflicts[j].FileName && t.conflicts[i].LineNo < t.conflicts[j].LineNo)
+	})
+	for _, c := range t.conflicts {
+		t.addError(newRouteError(20042, fmt.Sprintf("%s route, conflicts with %s FileName: %s LineNo: %d", c.Kind, c.OtherRoute, c.OtherFileName, c.OtherLineNo),
+			c.Method+" "+c.Route, c.FileName, c.LineNo))
+	}
+}
+
+// The path with the names of the params removed, /api/:id and /api/{name} are both /=api/:
+// and the literals start with "=".  A constraint is replaced by its regular expression,
+// so {id:int} is the same as {id:-?[0-9]+}.
+func (r *MuxRouter) routeSkeleton(route string, noCase bool) string {
+	segs, err := ParseRoute(route)
+	if err != nil {
+		return route
+	}
+	sk := ""
+	for _, s := range segs {
+		switch s.Kind {
+		case SegParam, SegVar:
+			sk += "/:"
+		case SegCatchAll:
+			sk += "/*"
+		case SegRegex:
+			sk += "/{" + r.skeletonRe(s) + "}"
+		case SegMixed:
+			sk += "/"
+			for _, p := range s.Parts {
+				switch p.Kind {
+				case SegLiteral:
+					sk += "=" + p.Text
+				case SegRegex:
+					sk += "{" + r.skeletonRe(p) + "}"
+				default:
+					sk += ":"
+				}
+			}
+		default:
+			if noCase {
+				sk += "/=" + strings.ToLower(s.Text)
+			} else {
+				sk += "/=" + s.Text // A literal "*" is not the same as *name
+			}
+		}
+	}
+	return sk
+}
+
+// The regular expression of a SegRegex without the anchors.
+func (r *MuxRouter) skeletonRe(s RouteSegment) string {
+	src, err := r.segmentSrc(s)
+	if err != nil {
+		return s.Re
+	}
+	return src
+}
+
+// The matchers on a route, other than the path and method, as a set.
+func routeConds(v *ARoute) map[string]bool {
+	c := make(map[string]bool)
+	if v.DHost != "" {
+		c["host="+v.DHost] = true
+	}
+	if v.DHostPort != "" {
+		c["hostport="+v.DHostPort] = true
+	}
+	if v.DPort != "" {
+		c["port="+v.DPort] = true
+	}
+	if ignore, plain, tls := isHttpHttps(v.DSchemes); !ignore && (plain || tls) {
+		c[fmt.Sprintf("tls=%v", tls)] = true
+	}
+	if !IsMapStringBoolEmpty(v.DProtocal) {
+		var p []string
+		for k, on := range v.DProtocal {
+			if on {
+				p = append(p, k)
+			}
+		}
+		sort.Strings(p)
+		c["protocal="+strings.Join(p, ",")] = true
+	}
+	for i := 0; i+1 < len(v.DHeaders); i += 2 {
+		c["header:"+http.CanonicalHeaderKey(v.DHeaders[i])+"="+v.DHeaders[i+1]] = true
+	}
+	for i := 0; i+1 < len(v.DQueries); i += 2 {
+		c["query:"+v.DQueries[i]+"="+v.DQueries[i+1]] = true
+	}
+	return c
+}
+
+// True if every matcher in a is in b.
+func subsetConds(a, b map[string]bool) bool {
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
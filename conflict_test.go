@@ -0,0 +1,120 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_RouteConflicts(t *testing.T) {
+	r := NewRouter()
+	a := r.HandleFunc("/api/:id", createFx(9601)).Methods("GET", "POST")
+	b := r.HandleFunc("/api/:name", createFx(9602)).Methods("GET")
+	r.HandleFunc("/users/list", createFx(9603))
+	c := r.HandleFunc("/users/list", createFx(9604))
+	r.HandleFunc("/beta", createFx(9605)).Headers("X-Beta", "")
+	d := r.HandleFunc("/beta", createFx(9606)).Headers("X-Beta", "", "X-Other", "1")
+	r.HandleFunc("/beta", createFx(9607)).Headers("X-Beta", "").Host("beta.example.com") // Tried first, it has more matchers
+	r.HandleFunc("/f", createFx(9608)).MatcherFunc(func(req *http.Request, rm *RouteMatch) bool { return true })
+	r.HandleFunc("/f", createFx(9609)).MatcherFunc(func(req *http.Request, rm *RouteMatch) bool { return true })
+	r.HandleFunc("/v/{id:[0-9]+}", createFx(9610))
+	r.HandleFunc("/v/{id:[a-z]+}", createFx(9611))
+	r.HandleFunc("/v/{x}", createFx(9612))
+	r.CompileRoutes()
+
+	got := r.RouteTable().Conflicts()
+	expect := []struct {
+		kind  ConflictKind
+		route *ARoute
+		other *ARoute
+	}{
+		{EquivalentRoute, b, a},
+		{DuplicateRoute, c, nil},
+		{ShadowedRoute, d, nil},
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("Expected %d conflicts, got %d: %v\n", len(expect), len(got), got)
+	}
+	for i, e := range expect {
+		if got[i].Kind != e.kind || got[i].LineNo != e.route.LineNo || got[i].FileName != e.route.FileName {
+			t.Errorf("Conflict %d: expected %s at line %d, got %s\n", i, e.kind, e.route.LineNo, got[i])
+		}
+		if e.other != nil && got[i].OtherLineNo != e.other.LineNo {
+			t.Errorf("Conflict %d: expected other at line %d, got %s\n", i, e.other.LineNo, got[i])
+		}
+	}
+	if got[0].Method != "GET" {
+		t.Errorf("Expected only the GET /api/:name to conflict, got %s\n", got[0])
+	}
+
+	// The shadowed route is not reached.
+	req := newTestRequest("GET", "/beta")
+	req.Header.Set("X-Beta", "1")
+	req.Header.Set("X-Other", "1")
+	r.ServeHTTP(new(mockResponseWriter), req)
+	if arrived != 9605 {
+		t.Errorf("Expected 9605 for /beta, got %d\n", arrived)
+	}
+
+	strict := NewRouter()
+	strict.StrictRoutes = true
+	strict.HandleFunc("/x/:a", createFx(9620))
+	strict.HandleFunc("/x/:b", createFx(9621))
+	err := strict.CompileRoutes()
+	if errs, ok := err.(RouteErrors); !ok || len(errs) != 1 || errs[0].Code != 20042 {
+		t.Errorf("Expected one Error(20042) with StrictRoutes, got %v\n", err)
+	}
+	arrived = 0
+	strict.ServeHTTP(new(mockResponseWriter), newTestRequest("GET", "/x/1"))
+	if arrived != 0 {
+		t.Errorf("Expected the table with errors not to be used, got %d\n", arrived)
+	}
+	strict.StrictRoutes = false
+	if err = strict.CompileRoutes(); err != nil {
+		t.Errorf("Expected no error without StrictRoutes, got %s\n", err)
+	}
+	strict.ServeHTTP(new(mockResponseWriter), newTestRequest("GET", "/x/1"))
+	if arrived != 9620 && arrived != 9621 {
+		t.Errorf("Expected the table to be used once StrictRoutes is off, got %d\n", arrived)
+	}
+
+	// A good table stays in use when a change with errors is rejected.
+	strict = NewRouter()
+	strict.StrictRoutes = true
+	strict.HandleFunc("/y/:a", createFx(9622))
+	strict.ServeHTTP(new(mockResponseWriter), newTestRequest("GET", "/y/1"))
+	strict.HandleFunc("/y/:b", createFx(9623))
+	arrived = 0
+	strict.ServeHTTP(new(mockResponseWriter), newTestRequest("GET", "/y/1"))
+	if arrived != 9622 || len(strict.Errors()) != 1 {
+		t.Errorf("Expected the old table to be used and one error, got %d %v\n", arrived, strict.Errors())
+	}
+
+	ci := NewRouter()
+	ci.CaseInsensitive = true
+	ci.HandleFunc("/Api", createFx(9624))
+	ci.HandleFunc("/api", createFx(9625))
+	ci.CompileRoutes()
+	if c := ci.RouteTable().Conflicts(); len(c) != 1 || c[0].Kind != EquivalentRoute {
+		t.Errorf("Expected /Api and /api to conflict on a case-insensitive router, got %v\n", c)
+	}
+
+	nc := NewRouter()
+	nc.HandleFunc("/n/{id:int}", createFx(9626))
+	nc.HandleFunc("/n/{id:-?[0-9]+}", createFx(9627))
+	nc.HandleFunc("/m/u{id:int}.txt", createFx(9628))
+	nc.HandleFunc("/m/u{id:^-?[0-9]+$}.txt", createFx(9629))
+	nc.CompileRoutes()
+	if c := nc.RouteTable().Conflicts(); len(c) != 2 || c[0].Kind != EquivalentRoute || c[1].Kind != EquivalentRoute {
+		t.Errorf("Expected a constraint and its regular expression to conflict, got %v\n", c)
+	}
+}
@@ -0,0 +1,142 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// The named constraints every router starts with, {id:int} is the same as {id:-?[0-9]+}.
+var defaultConstraints = map[string]string{
+	"int":  `-?[0-9]+`,
+	"uuid": `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+	"slug": `[a-z0-9]+(?:-[a-z0-9]+)*`,
+	"date": `[0-9]{4}-(?:0[1-9]|1[0-2])-(?:0[1-9]|[12][0-9]|3[01])`,
+}
+
+// Used for a SegMixed that has an error, there is nothing that it matches.
+var neverMatch = regexp.MustCompile(`[^\x00-\x{10FFFF}]`)
+
+// A named constraint, re is what was added and cRe is it anchored.
+type constraint struct {
+	re  string
+	cRe *regexp.Regexp
+}
+
+// Anchor a regular expression so that it has to match all of the segment.
+func anchorRe(re string) string {
+	return "^(?:" + re + ")$"
+}
+
+// AddConstraint adds a named constraint, or replaces one, that can be used in a route
+// as {name:constraint}.  The router starts with int, uuid, slug and date.  The regular
+// expression is compiled here, once, and has to match all of the segment.  In a route
+// {name:re} is a constraint if re is a name, [a-zA-Z_][a-zA-Z_0-9]*, that has been
+// added, otherwise re is a regular expression.  Write (?:abc) to always match the text abc.
+//
+//	r.AddConstraint("hex", "[0-9a-f]+")
+//	r.HandleFunc("/color/{rgb:hex}", colorHandler)
+func (r *MuxRouter) AddConstraint(name, re string) error {
+	if !validParamName.MatchString(name) {
+		return fmt.Errorf("gogomux: invalid constraint name %q", name)
+	}
+	cre, err := regexp.Compile(anchorRe(re))
+	if err != nil {
+		return fmt.Errorf("gogomux: constraint %s: %s", name, err)
+	}
+	r.constraintLock.Lock()
+	r.initConstraints()
+	r.constraints[name] = constraint{re: re, cRe: cre}
+	r.constraintLock.Unlock()
+	atomic.StoreInt32(&r.dirty, 1) // Routes that use it are compiled again
+	return nil
+}
+
+// Compile the defaults the first time they are needed.  The caller has the constraintLock.
+func (r *MuxRouter) initConstraints() {
+	if r.constraints == nil {
+		r.constraints = make(map[string]constraint)
+		for name, re := range defaultConstraints {
+			r.constraints[name] = constraint{re: re, cRe: regexp.MustCompile(anchorRe(re))}
+		}
+	}
+}
+
+// The regular expression for a SegRegex, anchored to the whole segment.  A constraint
+// is only looked up by name, an inline regular expression is never replaced by one.
+func (r *MuxRouter) segmentRe(s RouteSegment) (*regexp.Regexp, error) {
+	if s.Constraint == "" {
+		return regexp.Compile(anchorRe(s.Re))
+	}
+	return r.namedConstraint(s.Constraint).cRe, nil
+}
+
+// The regular expression for a SegRegex in a SegMixed, without the anchors.
+func (r *MuxRouter) segmentSrc(s RouteSegment) (string, error) {
+	if s.Constraint == "" {
+		return strings.TrimSuffix(strings.TrimPrefix(s.Re, "^"), "$"), nil
+	}
+	return r.namedConstraint(s.Constraint).re, nil
+}
+
+// Look up a constraint by name.  A name that has not been added is a regular expression
+// for the text, so {action:edit} matches edit as it did before there were constraints.
+func (r *MuxRouter) namedConstraint(name string) constraint {
+	r.constraintLock.Lock()
+	defer r.constraintLock.Unlock()
+	r.initConstraints()
+	c, ok := r.constraints[name]
+	if !ok {
+		c = constraint{re: name, cRe: regexp.MustCompile(anchorRe(name))}
+	}
+	return c
+}
+
+// The regular expression for a SegMixed, each param is a group with its name.  A param
+// takes as much as it can, so :name.:ext splits a.tar.gz into a.tar and gz.
+func (r *MuxRouter) mixedRe(s RouteSegment, noCase bool) (*regexp.Regexp, error) {
+	re := "^"
+	for _, p := range s.Parts {
+		switch p.Kind {
+		case SegLiteral:
+			if noCase {
+				re += "(?i:" + regexp.QuoteMeta(p.Text) + ")"
+			} else {
+				re += regexp.QuoteMeta(p.Text)
+			}
+		case SegRegex:
+			src, err := r.segmentSrc(p)
+			if err != nil {
+				return nil, err
+			}
+			re += "(?P<" + p.Name + ">" + src + ")"
+		default:
+			re += "(?P<" + p.Name + ">.+)"
+		}
+	}
+	return regexp.Compile(re + "$")
+}
+
+// The names of the params in a segment, in order.
+func segNames(s RouteSegment) (names []string) {
+	if s.Kind != SegMixed {
+		return []string{s.Name}
+	}
+	for _, p := range s.Parts {
+		if p.Kind != SegLiteral {
+			names = append(names, p.Name)
+		}
+	}
+	return
+}
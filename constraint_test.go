@@ -0,0 +1,99 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_Constraints(t *testing.T) {
+	r := NewRouter()
+	var got Params
+	save := func(ii int) HandleFunc {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			arrived, got = ii, ps.Copy()
+		}
+	}
+	r.HandleFunc("/re/{id:[0-9]+}", save(9901)).Methods("GET")
+	r.HandleFunc("/int/{id:int}", save(9902)).Methods("GET")
+	r.HandleFunc("/uuid/{id:uuid}", save(9903)).Methods("GET")
+	r.HandleFunc("/slug/{id:slug}", save(9904)).Methods("GET")
+	r.HandleFunc("/date/{id:date}", save(9905)).Methods("GET")
+	r.HandleFunc("/hex/{id:hex}", save(9906)).Methods("GET")
+	r.HandleFunc("/old/{id:^[0-9][0-9]*$}", save(9907)).Methods("GET")
+	r.HandleFunc("/alt/{id:a|b}", save(9908)).Methods("GET")
+	r.HandleFunc("/lit/{id:(?:hex)}", save(9910)).Methods("GET") // The text hex, not the constraint
+
+	tests := []struct {
+		url    string
+		expect int
+		value  string
+	}{
+		{"/re/123", 9901, "123"},
+		{"/re/abc123def", -1, ""},
+		{"/re/123abc", -1, ""},
+		{"/int/-42", 9902, "-42"},
+		{"/int/4.2", -1, ""},
+		{"/uuid/123e4567-e89b-12d3-a456-426614174000", 9903, "123e4567-e89b-12d3-a456-426614174000"},
+		{"/uuid/123e4567", -1, ""},
+		{"/slug/go-go-mux", 9904, "go-go-mux"},
+		{"/slug/-go", -1, ""},
+		{"/date/2015-06-30", 9905, "2015-06-30"},
+		{"/date/2015-13-30", -1, ""},
+		{"/hex/ff00", -1, ""}, // Not added yet, hex is a regular expression
+		{"/hex/hex", 9906, "hex"},
+		{"/old/12", 9907, "12"},
+		{"/alt/b", 9908, "b"},
+		{"/alt/ab", -1, ""},
+		{"/lit/hex", 9910, "hex"},
+		{"/lit/ff00", -1, ""},
+	}
+	check := func(when string) {
+		for i, test := range tests {
+			arrived, got = -1, Params{}
+			r.ServeHTTP(new(mockResponseWriter), newTestRequest("GET", test.url))
+			if arrived != test.expect {
+				t.Errorf("%s Test[%d] %s: expected %d, got %d", when, i, test.url, test.expect, arrived)
+			} else if test.value != "" && got.ByName("id") != test.value {
+				t.Errorf("%s Test[%d] %s: expected id=%s, got %s", when, i, test.url, test.value, got.DumpParam())
+			}
+		}
+	}
+	check("Before")
+	if re := r.Errors(); len(re) != 0 {
+		t.Errorf("Expected no errors for a name that is not a constraint, got %v", re)
+	}
+
+	if err := r.AddConstraint("hex", "[0-9a-f]+"); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	tests[11].expect, tests[11].value = 9906, "ff00"
+	tests[12].expect, tests[12].value = -1, ""
+	check("After")
+	if re := r.Errors(); len(re) != 0 {
+		t.Errorf("Expected no errors after AddConstraint, got %v", re)
+	}
+
+	if err := r.AddConstraint("bad name", "x"); err == nil {
+		t.Errorf("Expected an error for an invalid constraint name")
+	}
+	if err := r.AddConstraint("bad", "[0-9"); err == nil {
+		t.Errorf("Expected an error for an invalid regular expression")
+	}
+
+	if u, err := r.HandleFunc("/u/{id:int}", save(9909)).URLPath("id", "12x"); err == nil {
+		t.Errorf("Expected an error for 12x, got %s", u)
+	}
+	if r2 := NewRouter(); len(r2.constraints) != 0 {
+		t.Errorf("Expected the constraints to be compiled when they are used")
+	}
+}
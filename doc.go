@@ -47,6 +47,43 @@ variable will be anything until the next slash. For example:
 	r.HandleFunc("/articles/{category}/", ArticlesCategoryHandler)
 	r.HandleFunc("/articles/{category}/{id:[0-9]+}", ArticleHandler)
 
+The pattern has to match all of the segment, {id:[0-9]+} does not match abc123def.
+The pattern can also be the name of a constraint: int, uuid, slug or date, or one
+added with AddConstraint:
+
+	r.HandleFunc("/users/{id:int}", UserHandler)
+	r.AddConstraint("hex", "[0-9a-f]+")
+	r.HandleFunc("/color/{rgb:hex}", ColorHandler)
+
+A pattern that is a name is a constraint if one with that name has been added, if not
+it is a regular expression and {action:edit} matches the text edit.  Adding a constraint
+later changes what such a route matches.  To always match the text hex write {rgb:(?:hex)}.
+
+A segment can have more than one variable, and text around them.  A :name ends at
+the first character that can not be in a name, and a variable takes as much of the
+segment as it can:
+
+	r.HandleFunc("/files/:name.:ext", FileHandler)            // a.tar.gz is a.tar and gz
+	r.HandleFunc("/v{major}.{minor}/status", StatusHandler)
+	r.HandleFunc("/report-{year:[0-9]{4}}-{month}.csv", ReportHandler)
+
+A ':' right after a letter, digit or '_' is text, so /v1/users:batchGet is a literal.
+Use {name} before a ':' that is text, /v1/{res}\:batchGet, or \: after a '.' or '-'.
+
+A segment that starts with a '\' is matched as text, so a path with a ':', '*' or
+'{' at the start of a segment can be a route:
+
+	r.HandleFunc(`/ns/\:system`, SystemHandler)  // matches /ns/:system
+	r.HandleFunc(`/odata/\{set}`, SetHandler)    // matches /odata/{set}
+
+By default a trailing '/' is ignored, /api/:id matches /api/12/ and /docs/ matches /docs.
+Set StrictSlash to make it part of the route, then /api/:id does
+not match /api/12/.  This changes what matches, so it is off unless asked for.  With
+StrictSlash, set UseRedirect to send a 301, or a 308 for methods other than GET and HEAD,
+to the route that only differs in the trailing '/'.
+A path with //, /./ or /../ in it is cleaned and then matched, set RedirectFixed to
+redirect to the cleaned path instead.
+
 The names are used to create a map of route variables which can be retrieved
 calling mux.Vars():
 
@@ -85,12 +122,24 @@ There are several other matchers that can be added. To match path prefixes:
 
 	r.Queries("key", "value")
 
+Values in Headers and Queries can be "" to only check that the name is present, or
+{name} / {name:pattern} to match and put the value in the Params:
+
+	r.Queries("id", "{id:[0-9]+}", "debug", "")
+	r.Headers("X-Version", "{ver}")
+
 ...or to use a custom matcher function:
 
 	r.MatcherFunc(func(r *http.Request, rm *RouteMatch) bool {
 		return r.ProtoMajor == 0
     })
 
+The built in matchers are also available as functions, HostMatcher, PortMatcher,
+HostPortMatcher, HeaderMatcher, QueryMatcher, SchemeMatcher and ProtocalMatcher, so that
+they can be combined with Or, All and Not:
+
+	r.Matcher(Or(HeaderMatcher("X-Beta", ""), QueryMatcher("beta", "1")))
+
 ...and finally, it is possible to combine several matchers in a single route:
 
 	r.HandleFunc("/products", ProductsHandler).
@@ -115,10 +164,13 @@ Then register routes in the subrouter:
 	s.HandleFunc("/products/{key}", ProductHandler)
 	s.HandleFunc("/articles/{category}/{id:[0-9]+}"), ArticleHandler)
 
-The three URL paths we registered above will only be tested if the domain is
-"www.domain.com", because the subrouter is tested first. This is not
-only convenient, but also optimizes request matching. You can create
-subrouters combining any attribute matchers accepted by a route.
+The three URL paths we registered above will only match if the domain is
+"www.domain.com".  The routes get the host, port, schemes, headers, queries,
+methods, protocal, matcher functions and path prefix of the parent route when
+the routes are compiled, so they are in the same hash table as all the other
+routes and matching is just as fast.  You can create subrouters combining any
+attribute matchers accepted by a route.  A method set on a route in the
+subrouter is used instead of the methods from the parent.
 
 Subrouters can be used to create domain or path "namespaces": you define
 subrouters in a central place and then parts of the app can register its
@@ -0,0 +1,159 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hostTemplate is a compiled pattern from Host(), HostPort(), Port() or HostPort_AllRoutes().
+// The pattern can have variables, {name} or {name:re}, and '*' for any number of labels,
+//
+//	{subdomain:[a-z]+}.example.com
+//	*.example.com
+//	{tenant}.example.com:{port:[0-9]+}
+//
+// With no variables or '*' the text is compared directly.
+type hostTemplate struct {
+	text  string         // The pattern as it was set
+	re    *regexp.Regexp // nil if the text is compared directly
+	names []string       // Names of the variables, in order, "" for a '*'
+	index []int          // index[i] is the group in re for names[i]
+}
+
+// Default regular expression for a {name} with no :re, one label of a host name.
+const hostLabelRe = `[^.:]+`
+
+// One part of a host template, text, a {name:re} or a '*'.
+type hostPart struct {
+	text string // The text, or the {name:re} as it is in the template
+	name string // "" for text and '*'
+	re   string // The regular expression for a {name:re} or '*', "" for text
+}
+
+// Split a host template into its parts.  The '{' and '}' are counted, so the re in a
+// {name:re} can have its own {n}, {sub:[a-z]{2}}.example.com.
+func scanHostTemplate(tpl string) (parts []hostPart, err error) {
+	lit := 0
+	for i := 0; i < len(tpl); i++ {
+		switch tpl[i] {
+		case '{':
+			if i > lit {
+				parts = append(parts, hostPart{text: tpl[lit:i]})
+			}
+			j, d := i, 0
+			for ; j < len(tpl); j++ {
+				if tpl[j] == '{' {
+					d++
+				} else if tpl[j] == '}' {
+					if d--; d == 0 {
+						break
+					}
+				}
+			}
+			if j >= len(tpl) {
+				return nil, fmt.Errorf("missing } in host template %s", tpl)
+			}
+			name, re := tpl[i+1:j], hostLabelRe
+			if colon := strings.IndexByte(name, ':'); colon >= 0 {
+				name, re = name[:colon], strings.TrimSuffix(strings.TrimPrefix(name[colon+1:], "^"), "$")
+			}
+			if name == "" {
+				return nil, fmt.Errorf("missing name in host template %s", tpl)
+			}
+			parts = append(parts, hostPart{text: tpl[i : j+1], name: name, re: re})
+			i, lit = j, j+1
+		case '*':
+			if i > lit {
+				parts = append(parts, hostPart{text: tpl[lit:i]})
+			}
+			parts = append(parts, hostPart{text: "*", re: "(?:" + hostLabelRe + `\.)*` + hostLabelRe})
+			lit = i + 1
+		}
+	}
+	if lit < len(tpl) {
+		parts = append(parts, hostPart{text: tpl[lit:]})
+	}
+	return
+}
+
+// Compile a host template.  Each {name:re} and '*' is a named group, h0, h1 ..., in the
+// regular expression, so a group in the re does not move the ones after it.
+func compileHostTemplate(tpl string) (ht *hostTemplate, err error) {
+	ht = &hostTemplate{text: tpl}
+	if strings.IndexAny(tpl, "{*") < 0 {
+		return
+	}
+	parts, err := scanHostTemplate(tpl)
+	if err != nil {
+		return nil, err
+	}
+	pat := "^"
+	for _, p := range parts {
+		if p.re == "" {
+			pat += regexp.QuoteMeta(p.text)
+			continue
+		}
+		pat += fmt.Sprintf("(?P<h%d>%s)", len(ht.names), p.re)
+		ht.names = append(ht.names, p.name)
+	}
+	if ht.re, err = regexp.Compile(pat + "$"); err != nil {
+		return nil, err
+	}
+	for i := range ht.names {
+		ht.index = append(ht.index, ht.re.SubexpIndex(fmt.Sprintf("h%d", i)))
+	}
+	return
+}
+
+// Compile a host template for a route, on error the text is compared directly.
+func routeHostTemplate(tpl string, FileName string, LineNo int) (*hostTemplate, *RouteError) {
+	ht, err := compileHostTemplate(tpl)
+	if err != nil {
+		return &hostTemplate{text: tpl}, newRouteError(20040, err.Error(), tpl, FileName, LineNo)
+	}
+	return ht, nil
+}
+
+// Compile a host template for a route in the table, errors are kept in the table.
+func (t *RouteTable) hostTemplate(tpl string, FileName string, LineNo int) *hostTemplate {
+	ht, e := routeHostTemplate(tpl, FileName, LineNo)
+	t.addError(e)
+	return ht
+}
+
+// Match s to the template.  The variables are added to ps, From: FromHost.
+func (ht *hostTemplate) match(s string, ps *Params) bool {
+	if ht.re == nil {
+		return ht.text == s
+	}
+	m := ht.re.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	for i, name := range ht.names {
+		if name != "" {
+			AddValueToParams(name, m[ht.index[i]], 'h', FromHost, ps)
+		}
+	}
+	return true
+}
+
+// Split a Host header into host and port, the port is "" if there is none.
+func splitHostPort(hp string) (host, port string) {
+	colon := LastIndexOfChar(hp, ':')
+	if colon == -1 || LastIndexOfChar(hp, ']') > colon { // No port, or an IPv6 address with no port
+		return hp, ""
+	}
+	return hp[:colon], hp[colon+1:]
+}
@@ -0,0 +1,122 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_HostTemplate(t *testing.T) {
+	var hostTemplateTests = []struct {
+		Tpl   string
+		Host  string
+		Match bool
+		Name  string
+		Value string
+	}{
+		{"example.com", "example.com", true, "", ""},
+		{"example.com", "www.example.com", false, "", ""},
+		{"{sub:[a-z]+}.example.com", "acme.example.com", true, "sub", "acme"},
+		{"{sub:[a-z]+}.example.com", "acme1.example.com", false, "", ""},
+		{"{sub:^[a-z]+$}.example.com", "acme.example.com", true, "sub", "acme"},
+		{"{sub}.example.com", "a-b.example.com", true, "sub", "a-b"},
+		{"{sub}.example.com", "a.b.example.com", false, "", ""},
+		{"*.example.com", "a.b.example.com", true, "", ""},
+		{"*.example.com", "example.com", false, "", ""},
+		{"{tenant}.example.com:{port:[0-9]+}", "acme.example.com:8080", true, "port", "8080"},
+		{"{sub:[a-z]{2}}.example.com", "ab.example.com", true, "sub", "ab"},
+		{"{sub:[a-z]{2}}.example.com", "abc.example.com", false, "", ""},
+		{"{a:(x|y)z}.{b}.example.com", "yz.acme.example.com", true, "b", "acme"},
+		{"{a:(x|y)z}.{b}.example.com", "yz.acme.example.com", true, "a", "yz"},
+		{"*.{b}.example.com", "x.y.acme.example.com", true, "b", "acme"},
+	}
+	for i, test := range hostTemplateTests {
+		ht, err := compileHostTemplate(test.Tpl)
+		if err != nil {
+			t.Errorf("Test %d: %s unexpected error %s\n", i, test.Tpl, err)
+			continue
+		}
+		var ps Params
+		InitParams(&ps)
+		if got := ht.match(test.Host, &ps); got != test.Match {
+			t.Errorf("Test %d: %s with %s expected %v got %v\n", i, test.Tpl, test.Host, test.Match, got)
+		}
+		if test.Name != "" {
+			if v, found := ps.GetByNameAndType(test.Name, FromHost); !found || v != test.Value {
+				t.Errorf("Test %d: %s with %s expected %s=%s got ->%s<-\n", i, test.Tpl, test.Host, test.Name, test.Value, v)
+			}
+		}
+	}
+
+	for _, tpl := range []string{"{sub.example.com", "{sub:[a-z]{2}.example.com", "{:x}.example.com"} {
+		if _, err := compileHostTemplate(tpl); err == nil {
+			t.Errorf("Expected an error for %s\n", tpl)
+		}
+	}
+}
+
+func Test_HostRouting(t *testing.T) {
+	var got string
+	r := NewRouter()
+	r.HandleFunc("/dash", func(w http.ResponseWriter, req *http.Request, ps Params) {
+		arrived = 9401
+		got = ps.ByName("tenant")
+	}).Methods("GET").Host("{tenant:[a-z]+}.example.com")
+	r.HandleFunc("/dash", createFx(9402)).Methods("GET").Host("*.other.com")
+	r.HandleFunc("/dash", createFx(9403)).Methods("GET")
+
+	var hostTests = []struct {
+		Host    string
+		Arrived int
+		Tenant  string
+	}{
+		{"acme.example.com", 9401, "acme"},
+		{"acme.example.com:8080", 9401, "acme"},
+		{"a.b.other.com", 9402, ""},
+		{"acme9.example.com", 9403, ""},
+		{"localhost:8080", 9403, ""},
+	}
+	for i, test := range hostTests {
+		arrived, got = 0, ""
+		req := newTestRequest("GET", "/dash")
+		req.Host = test.Host
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if arrived != test.Arrived || got != test.Tenant {
+			t.Errorf("Test %d: %s expected %d ->%s<- got %d ->%s<-\n", i, test.Host, test.Arrived, test.Tenant, arrived, got)
+		}
+	}
+}
+
+func Test_HostPortAllRoutes(t *testing.T) {
+	r := NewRouter()
+	r.HostPort_AllRoutes("{sub}.example.com:8080", "localhost:8080")
+	r.HandleFunc("/ping", createFx(9411)).Methods("GET")
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	for i, test := range []struct {
+		Host    string
+		Arrived int
+	}{
+		{"api.example.com:8080", 9411},
+		{"localhost:8080", 9411},
+		{"api.example.com:9000", -1},
+	} {
+		arrived = 0
+		req := newTestRequest("GET", "/ping")
+		req.Host = test.Host
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if arrived != test.Arrived {
+			t.Errorf("Test %d: %s expected %d got %d\n", i, test.Host, test.Arrived, arrived)
+		}
+	}
+}
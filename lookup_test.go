@@ -0,0 +1,88 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import "testing"
+
+func Test_Lookup(t *testing.T) {
+	r := NewRouter()
+	x := r.HandleFunc("/users/:user/repos/:repo", createFx(8001)).Methods("GET")
+	r.HandleFunc("/hdr", createFx(8002)).Methods("GET").Headers("X-Test", "yes")
+
+	fx, ps, route, found := r.Lookup("GET", "/users/bob/repos/mux")
+	if !found {
+		t.Fatalf("Expected to find /users/bob/repos/mux\n")
+	}
+	if route != x {
+		t.Errorf("Expected the route that was registered\n")
+	}
+	if ps.ByName("user") != "bob" || ps.ByName("repo") != "mux" {
+		t.Errorf("Expected user=bob repo=mux, got %s\n", ps.DumpParam())
+	}
+	fx(nil, nil, ps)
+	if arrived != 8001 {
+		t.Errorf("Expected 8001, got %d\n", arrived)
+	}
+
+	// The params are a copy, a 2nd lookup does not change them.
+	r.Lookup("GET", "/users/sam/repos/other")
+	if ps.ByName("user") != "bob" {
+		t.Errorf("Expected params to be kept, got %s\n", ps.DumpParam())
+	}
+
+	if _, _, _, found = r.Lookup("POST", "/users/bob/repos/mux"); found {
+		t.Errorf("Expected no match for POST\n")
+	}
+
+	if _, _, _, found = r.Lookup("GET", "/hdr"); found {
+		t.Errorf("Expected no match for /hdr without the header\n")
+	}
+	req := newTestRequest("GET", "/hdr")
+	req.Header.Set("X-Test", "yes")
+	if _, _, _, found = r.Lookup("GET", "/hdr", req); !found {
+		t.Errorf("Expected a match for /hdr with the request\n")
+	}
+}
+
+func Test_Match(t *testing.T) {
+	r := NewRouter()
+	x := r.HandleFunc("/orgs/:org/members/:member", createFx(8010)).Methods("GET")
+	r.HandleFunc("/orgs/list", createFx(8011)).Methods("GET")
+
+	arrived = 0
+	rm, found := r.Match(newTestRequest("GET", "/orgs/acme/members/sam"))
+	if !found {
+		t.Fatalf("Expected to match /orgs/acme/members/sam\n")
+	}
+	if arrived != 0 {
+		t.Errorf("Match should not call the handler, got %d\n", arrived)
+	}
+	if rm.Route != x {
+		t.Errorf("Expected the route that was registered\n")
+	}
+	if rm.Params.ByName("org") != "acme" || rm.Params.ByName("member") != "sam" {
+		t.Errorf("Expected org=acme member=sam, got %s\n", rm.Params.DumpParam())
+	}
+	if rm.UsePat != "T:T:" {
+		t.Errorf("Expected pattern T:T:, got %s\n", rm.UsePat)
+	}
+	if rm.CType&(SingleUrl|MultiUrl) == 0 {
+		t.Errorf("Expected a SingleUrl or MultiUrl entry, got %s\n", rm.CType)
+	}
+	rm.Handler(nil, nil, *rm.Params)
+	if arrived != 8010 {
+		t.Errorf("Expected 8010, got %d\n", arrived)
+	}
+
+	if _, found = r.Match(newTestRequest("GET", "/orgs/acme/other")); found {
+		t.Errorf("Expected no match for /orgs/acme/other\n")
+	}
+}
@@ -0,0 +1,67 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import "net/url"
+
+// MatchState holds everything that is computed for a single request while it is
+// being routed.  The compiled RouteTable is read-only after it is built, so all of
+// the per-request information lives here instead.  A MatchState is taken
+// from a sync.Pool at the top of ServeHTTP and returned at the bottom, so there is no
+// allocation per request.
+//
+// Note: the Params passed to a handler point into the MatchState.  A handler that
+// needs the values after it returns (in a goroutine for example) must copy them.
+type MatchState struct {
+	CurUrl   string                 // The current URL being processed.
+	Hash     [MaxSlashInUrl]int     // The set of hash keys in the current operation.
+	Slash    [MaxSlashInUrl + 1]int // Array of locaitons for the '/' in the url.  For /abc/def, it would be [ 0, 4, 8 ]
+	NSl      int                    // Number of slashes in the URL for /abc/def it would be 2
+	AllParam Params                 // The parameters for the current operation
+	UsePat   string                 // The used T::T pattern for matching - at URL time.
+
+	rt     *RouteTable // The compiled routes that this request is matched against
+	noCase bool        // Hash and compare words without case
+	slash  bool        // Without StrictSlash, split a trailing '/' as an empty word, for /static/*rest
+	early  bool        // SplitOnSlash3 took the early exit, no route can match
+	query  url.Values  // Parsed query, nil until a route matches on Queries()
+	rm     RouteMatch  // Passed to MatcherFunc functions
+
+	www MyResponseWriter // Wrapper for the http.ResponseWriter - kept here so it is not allocated per request
+}
+
+// NewMatchState returns a MatchState that is ready to use.  This is mostly used for
+// testing and at compile time, ServeHTTP uses the pool in the router.
+func NewMatchState() *MatchState {
+	ms := &MatchState{}
+	InitParams(&ms.AllParam)
+	return ms
+}
+
+// Get a MatchState from the pool and reset it for a new request.
+func (r *MuxRouter) getMatchState() (ms *MatchState) {
+	ms = r.statePool.Get().(*MatchState)
+	InitParams(&ms.AllParam)
+	ms.CurUrl = ""
+	ms.UsePat = ""
+	ms.NSl = 0
+	ms.query = nil
+	return
+}
+
+// Return a MatchState to the pool.
+func (r *MuxRouter) putMatchState(ms *MatchState) {
+	ms.www.w = nil
+	ms.www.discardBody = false // MatchAndServeHTTP does not reset it
+	ms.rt = nil                // Do not keep an old table alive from the pool
+	ms.rm = RouteMatch{}
+	r.statePool.Put(ms)
+}
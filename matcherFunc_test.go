@@ -0,0 +1,61 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_MatcherFunc(t *testing.T) {
+	w := new(mockResponseWriter)
+	r := NewRouter()
+	beta := func(req *http.Request, rm *RouteMatch) bool {
+		return req.Header.Get("X-Beta") == "on"
+	}
+	var tenant string
+	var route *ARoute
+	isAcme := func(req *http.Request, rm *RouteMatch) bool {
+		tenant = rm.Params.ByName("tenant")
+		route = rm.Route
+		return tenant == "acme"
+	}
+	r.HandleFunc("/feature/:id", createFx(9101)).Methods("GET")
+	r.HandleFunc("/feature/:id", createFx(9102)).Methods("GET").MatcherFunc(beta)
+	x := r.HandleFunc("/feature/:id", createFx(9103)).Methods("GET").MatcherFunc(beta).MatcherFunc(isAcme).Host("{tenant}.example.com")
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	tests := []struct {
+		host   string
+		beta   string
+		expect int
+	}{
+		{"localhost:8080", "", 9101},
+		{"localhost:8080", "on", 9102},
+		{"acme.example.com", "on", 9103},
+		{"other.example.com", "on", 9102},
+	}
+	for i, test := range tests {
+		arrived = 0
+		req := newTestRequest("GET", "/feature/12")
+		req.Host = test.host
+		if test.beta != "" {
+			req.Header.Set("X-Beta", test.beta)
+		}
+		r.ServeHTTP(w, req)
+		if arrived != test.expect {
+			t.Errorf("Test %d: expected %d, got %d\n", i, test.expect, arrived)
+		}
+	}
+	if tenant != "other" || route != x {
+		t.Errorf("Expected the matcher to get the host params and the route, got tenant=%s\n", tenant)
+	}
+}
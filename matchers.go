@@ -0,0 +1,189 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// The built in matchers as Matcher values so that they can be combined with Or, All
+// and Not.  Each one does the same match as the ARoute function with the same name.
+//
+//	r.HandleFunc("/new-ui", newUI).Methods("GET").Matcher(Or(
+//		HeaderMatcher("X-Beta", ""),
+//		MatcherFunc(func(req *http.Request, rm *RouteMatch) bool {
+//			c, err := req.Cookie("beta")
+//			return err == nil && c.Value == "1"
+//		}),
+//	))
+//
+// Errors in a host template or a {name:re} value are kept in the Matcher, with the
+// file and line where it was made, and are reported by Errors() for the route that
+// it is added to.
+
+// Matcher is a request matcher.  A MatcherFunc is a Matcher.
+type Matcher interface {
+	Match(req *http.Request, rm *RouteMatch) bool
+}
+
+// Match calls f(req, rm).
+func (f MatcherFunc) Match(req *http.Request, rm *RouteMatch) bool {
+	return f(req, rm)
+}
+
+// A Matcher with the errors from building it.
+type matcher struct {
+	f      MatcherFunc
+	errors []*RouteError
+}
+
+func (m *matcher) Match(req *http.Request, rm *RouteMatch) bool {
+	return m.f(req, rm)
+}
+
+func newMatcher(f MatcherFunc, e *RouteError) *matcher {
+	m := &matcher{f: f}
+	if e != nil {
+		m.errors = append(m.errors, e)
+	}
+	return m
+}
+
+// The errors from building m and the matchers in it.
+func matcherErrors(m ...Matcher) (rv []*RouteError) {
+	for _, v := range m {
+		if x, ok := v.(*matcher); ok {
+			rv = append(rv, x.errors...)
+		}
+	}
+	return
+}
+
+// HostMatcher matches the host without the port, see Host.
+func HostMatcher(tpl string) Matcher {
+	fn, ln := LineFile(2)
+	ht, e := routeHostTemplate(tpl, fn, ln)
+	return newMatcher(func(req *http.Request, rm *RouteMatch) bool {
+		host, _ := splitHostPort(req.Host)
+		return ht.match(host, rm.Params)
+	}, e)
+}
+
+// PortMatcher matches the port, 80 if there is none, see Port.
+func PortMatcher(tpl string) Matcher {
+	fn, ln := LineFile(2)
+	ht, e := routeHostTemplate(tpl, fn, ln)
+	return newMatcher(func(req *http.Request, rm *RouteMatch) bool {
+		_, port := splitHostPort(req.Host)
+		if port == "" {
+			port = "80"
+		}
+		return ht.match(port, rm.Params)
+	}, e)
+}
+
+// HostPortMatcher matches the host and port, see HostPort.
+func HostPortMatcher(tpl string) Matcher {
+	fn, ln := LineFile(2)
+	ht, e := routeHostTemplate(tpl, fn, ln)
+	return newMatcher(func(req *http.Request, rm *RouteMatch) bool {
+		return ht.match(req.Host, rm.Params)
+	}, e)
+}
+
+// HeaderMatcher matches name, value pairs in the headers, see Headers.
+func HeaderMatcher(pairs ...string) Matcher {
+	fn, ln := LineFile(2)
+	vms, e := compileValueMatch(pairs, true, fn, ln)
+	return newMatcher(func(req *http.Request, rm *RouteMatch) bool {
+		return matchValues(vms, req.Header, FromHeader, 'H', rm.Params)
+	}, e)
+}
+
+// QueryMatcher matches name, value pairs in the query, see Queries.
+func QueryMatcher(pairs ...string) Matcher {
+	fn, ln := LineFile(2)
+	vms, e := compileValueMatch(pairs, false, fn, ln)
+	return newMatcher(func(req *http.Request, rm *RouteMatch) bool {
+		q, _ := url.ParseQuery(req.URL.RawQuery)
+		return matchValues(vms, q, FromParams, 'q', rm.Params)
+	}, e)
+}
+
+// SchemeMatcher matches "http" or "https", see Schemes.
+func SchemeMatcher(schemes ...string) Matcher {
+	ignore, plain, tls := isHttpHttps(schemes)
+	return MatcherFunc(func(req *http.Request, rm *RouteMatch) bool {
+		if ignore {
+			return true
+		}
+		return (tls && req.TLS != nil) || (plain && req.TLS == nil)
+	})
+}
+
+// ProtocalMatcher matches the protocal, "HTTP/1.1" for example, see Protocal.
+func ProtocalMatcher(p ...string) Matcher {
+	return MatcherFunc(func(req *http.Request, rm *RouteMatch) bool {
+		for _, v := range p {
+			if req.Proto == v {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Or matches if any of the matchers match.  The first one that matches is used, the
+// values from the ones that did not match are not kept.  The errors from m are kept.
+func Or(m ...Matcher) Matcher {
+	return &matcher{f: func(req *http.Request, rm *RouteMatch) bool {
+		nParam := rm.Params.NParam
+		for _, f := range m {
+			if f.Match(req, rm) {
+				return true
+			}
+			truncParams(rm.Params, nParam)
+		}
+		return false
+	}, errors: matcherErrors(m...)}
+}
+
+// All matches if all of the matchers match.  This is the same as calling Matcher
+// for each, it is used inside of Or and Not.
+func All(m ...Matcher) Matcher {
+	return &matcher{f: func(req *http.Request, rm *RouteMatch) bool {
+		nParam := rm.Params.NParam
+		for _, f := range m {
+			if !f.Match(req, rm) {
+				truncParams(rm.Params, nParam)
+				return false
+			}
+		}
+		return true
+	}, errors: matcherErrors(m...)}
+}
+
+// Not matches if m does not match.  No values are kept from m.
+func Not(m Matcher) Matcher {
+	return &matcher{f: func(req *http.Request, rm *RouteMatch) bool {
+		nParam := rm.Params.NParam
+		b := m.Match(req, rm)
+		truncParams(rm.Params, nParam)
+		return !b
+	}, errors: matcherErrors(m)}
+}
+
+// Drop the values added after the first n, used when a matcher does not match.
+func truncParams(ps *Params, n int) {
+	ps.NParam = n
+	ps.search_ready = false
+}
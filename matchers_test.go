@@ -0,0 +1,82 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_MatcherCombinators(t *testing.T) {
+	w := new(mockResponseWriter)
+	r := NewRouter()
+	var got Params
+	save := func(n int) HandleFunc {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			arrived = n
+			got = ps.Copy()
+		}
+	}
+	betaCookie := MatcherFunc(func(req *http.Request, rm *RouteMatch) bool {
+		c, err := req.Cookie("beta")
+		return err == nil && c.Value == "1"
+	})
+	r.HandleFunc("/ui", save(9201)).Methods("GET")
+	r.HandleFunc("/ui", save(9202)).Methods("GET").Matcher(Or(HeaderMatcher("X-Beta", ""), betaCookie))
+	r.HandleFunc("/t", save(9203)).Methods("GET").Matcher(Or(
+		All(HostMatcher("{tenant}.example.com"), Not(QueryMatcher("legacy", ""))),
+		QueryMatcher("tenant", "{tenant:[a-z]+}"),
+	))
+	r.HandleFunc("/s", save(9204)).Methods("GET").Matcher(All(SchemeMatcher("http"), ProtocalMatcher("HTTP/1.1"), PortMatcher("8080")))
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	tests := []struct {
+		url    string
+		host   string
+		header string
+		cookie string
+		expect int
+		tenant string
+	}{
+		{"/ui", "", "", "", 9201, ""},
+		{"/ui", "", "1", "", 9202, ""},
+		{"/ui", "", "", "beta=1", 9202, ""},
+		{"/ui", "", "", "beta=0", 9201, ""},
+		{"/t", "acme.example.com", "", "", 9203, "acme"},
+		{"/t?legacy", "acme.example.com", "", "", -1, ""},
+		{"/t?legacy&tenant=bob", "acme.example.com", "", "", 9203, "bob"},
+		{"/s", "", "", "", 9204, ""},
+		{"/s", "localhost:9000", "", "", -1, ""},
+	}
+	for i, test := range tests {
+		arrived = 0
+		got = Params{}
+		req := newTestRequest("GET", test.url)
+		req.Proto = "HTTP/1.1"
+		if test.host != "" {
+			req.Host = test.host
+		}
+		if test.header != "" {
+			req.Header.Set("X-Beta", test.header)
+		}
+		if test.cookie != "" {
+			req.Header.Set("Cookie", test.cookie)
+		}
+		r.ServeHTTP(w, req)
+		if arrived != test.expect {
+			t.Errorf("Test %d: %s expected %d, got %d\n", i, test.url, test.expect, arrived)
+			continue
+		}
+		if test.tenant != "" && got.ByName("tenant") != test.tenant {
+			t.Errorf("Test %d: expected tenant=%s, got %s\n", i, test.tenant, got.DumpParam())
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_ParseRouteMixed(t *testing.T) {
+	segs, err := ParseRoute("/report-{year:[0-9]{4}}-{month}.csv")
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if len(segs) != 1 || segs[0].Kind != SegMixed {
+		t.Fatalf("Expected one mixed segment, got %v", segs)
+	}
+	kinds := []SegmentKind{SegLiteral, SegRegex, SegLiteral, SegVar, SegLiteral}
+	texts := []string{"report-", "{year:[0-9]{4}}", "-", "{month}", ".csv"}
+	if len(segs[0].Parts) != len(kinds) {
+		t.Fatalf("Expected %d parts, got %v", len(kinds), segs[0].Parts)
+	}
+	for i, p := range segs[0].Parts {
+		if p.Kind != kinds[i] || p.Text != texts[i] {
+			t.Errorf("Part %d: expected %s %q, got %s %q", i, kinds[i], texts[i], p.Kind, p.Text)
+		}
+	}
+	if n := segNames(segs[0]); len(n) != 2 || n[0] != "year" || n[1] != "month" {
+		t.Errorf("Expected names year, month, got %v", n)
+	}
+}
+
+func Test_MixedSegment(t *testing.T) {
+	r := NewRouter()
+	var got Params
+	save := func(ii int) HandleFunc {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			arrived, got = ii, ps.Copy()
+		}
+	}
+	r.HandleFunc("/files/:name.:ext", save(9951)).Methods("GET")
+	r.HandleFunc("/files/readme", save(9952)).Methods("GET")
+	r.HandleFunc("/v{major}.{minor}/status", save(9953)).Methods("GET")
+	r.HandleFunc("/report-{year:[0-9]{4}}-{month}.csv", save(9954)).Methods("GET")
+	r.HandleFunc("/user/u{id:int}", save(9955)).Methods("GET")
+	r.HandleFunc("/:lang/about", save(9958)).Methods("GET") // A param first, no early exit on the first word
+	if err := r.CompileRoutes(); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	tests := []struct {
+		url    string
+		expect int
+		params []string
+	}{
+		{"/files/main.go", 9951, []string{"name", "main", "ext", "go"}},
+		{"/files/a.tar.gz", 9951, []string{"name", "a.tar", "ext", "gz"}},
+		{"/files/readme", 9952, nil},
+		{"/files/noext", -1, nil},
+		{"/v1.2/status", 9953, []string{"major", "1", "minor", "2"}},
+		{"/v1/status", -1, nil},
+		{"/report-2015-06.csv", 9954, []string{"year", "2015", "month", "06"}},
+		{"/report-15-06.csv", -1, nil},
+		{"/report-2015-06.txt", -1, nil},
+		{"/user/u42", 9955, []string{"id", "42"}},
+		{"/user/uabc", -1, nil},
+		{"/en/about", 9958, []string{"lang", "en"}},
+	}
+	for i, test := range tests {
+		arrived, got = -1, Params{}
+		r.ServeHTTP(new(mockResponseWriter), newTestRequest("GET", test.url))
+		if arrived != test.expect {
+			t.Errorf("Test[%d] %s: expected %d, got %d", i, test.url, test.expect, arrived)
+			continue
+		}
+		for j := 0; j < len(test.params); j += 2 {
+			if v := got.ByName(test.params[j]); v != test.params[j+1] {
+				t.Errorf("Test[%d] %s: expected %s=%s, got %s", i, test.url, test.params[j], test.params[j+1], got.DumpParam())
+			}
+		}
+	}
+
+	u, err := r.HandleFunc("/dl/:name.:ext", save(9956)).URLPath("name", "a b", "ext", "txt")
+	if err != nil || u.Path != "/dl/a b.txt" || u.EscapedPath() != "/dl/a%20b.txt" {
+		t.Errorf("Expected /dl/a%%20b.txt, got %v %v", u, err)
+	}
+	if _, err := r.HandleFunc("/rp/r{y:[0-9]{4}}.csv", save(9957)).URLPath("y", "15"); err == nil {
+		t.Errorf("Expected an error for y=15")
+	}
+}
+
+func Test_ParamFirst(t *testing.T) {
+	r := NewRouter()
+	var got Params
+	save := func(ii int) HandleFunc {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			arrived, got = ii, ps.Copy()
+		}
+	}
+	r.HandleFunc("/:name", save(9961)).Methods("GET")
+	r.HandleFunc("/:lang/about", save(9962)).Methods("GET")
+	r.HandleFunc("/api/:id", save(9963)).Methods("GET", "POST")
+	r.HandleFunc("/*path", save(9964)).Methods("PUT")
+	if err := r.CompileRoutes(); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	get, post := MethodToCode("GET", 0), MethodToCode("POST", 0)
+	if r.RouteTable().paramFirst[get] != 1<<1|1<<2 || r.RouteTable().paramFirst[post] != 0 {
+		t.Errorf("Expected GET to have routes with 1 and 2 words that start with a param, got %b %b", r.RouteTable().paramFirst[get], r.RouteTable().paramFirst[post])
+	}
+
+	// The first word is still used to exit early for the lengths that have no such route.
+	ms := NewMatchState()
+	for i, test := range []struct {
+		url   string
+		early bool
+	}{
+		{"/x/y/z", true},
+		{"/x/y", false},
+		{"/x/y/", false},
+		{"/x//y/z", false},
+		{"/api/7/z", false},
+	} {
+		r.SplitOnSlash3(ms, get, test.url, true)
+		if ms.early != test.early || (test.early && ms.NSl != 1) {
+			t.Errorf("Test[%d] %s: expected early exit %v, got %v with %d words", i, test.url, test.early, ms.early, ms.NSl)
+		}
+	}
+
+	tests := []struct {
+		method string
+		url    string
+		expect int
+		name   string
+		value  string
+	}{
+		{"GET", "/a", 9961, "name", "a"},
+		{"GET", "/a/b/c", -1, "", ""},
+		{"GET", "/en/about/", 9962, "lang", "en"},
+		{"GET", "/en/about", 9962, "lang", "en"},
+		{"GET", "/api/7", 9963, "id", "7"},
+		{"POST", "/api/7", 9963, "id", "7"},
+		{"POST", "/en/about", -1, "", ""},
+		{"POST", "/a", -1, "", ""},
+		{"PUT", "/a/b/c", 9964, "path", "a/b/c"},
+	}
+	for i, test := range tests {
+		arrived, got = -1, Params{}
+		r.ServeHTTP(new(mockResponseWriter), newTestRequest(test.method, test.url))
+		if arrived != test.expect {
+			t.Errorf("Test[%d] %s %s: expected %d, got %d", i, test.method, test.url, test.expect, arrived)
+		} else if test.name != "" && got.ByName(test.name) != test.value {
+			t.Errorf("Test[%d] %s %s: expected %s=%s, got %s", i, test.method, test.url, test.name, test.value, got.DumpParam())
+		}
+	}
+}
@@ -19,31 +19,28 @@ package gogomux
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	// "./context" // "github.com/gorilla/context"
-
-	debug "github.com/pschlump/godebug"
 )
 
 // NewRouter returns a new router instance.
 func NewRouter() *MuxRouter {
 	r := &MuxRouter{
 		HasBeenCompiled: false,
-		MaxSlash:        1,
 		gen_hdlr:        1,
-		nLookupResults:  1,
 		AllHostPortFlag: false,
+		dirty:           1,
 	}
-	fn, ln := LineFile(1)
-	r.LookupResults = append(r.LookupResults, Collision2{cType: Dummy, FileName: fn, LineNo: ln})
-	// r.AllParam.Data = r.allParam[:]	// Must be created on stack // PJS Sun Nov 15 13:02:42 MST 2015
-	// r.AllParam.parent = r // PJS Sun Nov 15 13:12:31 MST 2015
-	r.AllParam.search = make(map[string]int)
-	r.Hash2Test = make([]int, bitMask+1, bitMask+1)
 	r.NotFound = http.NotFound // Set to default, http.NotFound handler.
+	r.statePool.New = func() interface{} { return NewMatchState() }
 	/// r.www = &r.www0
 
 	return r
@@ -71,42 +68,66 @@ func NewRouter() *MuxRouter {
 type MuxRouter struct {
 	NotFoundHandler http.Handler // Configurable Handler to be used when no route matches.
 	routes          []*ARoute    // Routes to be matched, from longes to shotest
-	UseRedirect     bool         // PJS
+	StrictSlash     bool         // A trailing '/' is part of the route, /api/:id does not match /api/12/, read when the routes are compiled
+	UseRedirect     bool         // With StrictSlash, redirect (301, or 308 if not GET/HEAD) when only a trailing slash differs from a route
+	RedirectFixed   bool         // Redirect to the cleaned path when the URL has //, /./ or /../ in it, instead of serving it
+	CaseInsensitive bool         // Match the words in all routes without case, params keep the case from the URL
+	RedirectCase    bool         // Redirect to the case used in the route when a case-insensitive route matches
+	UseRawPath      bool         // Route on req.URL.EscapedPath() so %2F in a param does not split it, params are unescaped
+	StrictRoutes    bool         // A table with any errors, duplicate, equivalent or shadowed routes too, is not used, see CompileRoutes
 
 	AllHostPortFlag bool
 	AllHostPort     map[string]int
 
 	// ------------------------------------------------------------------------------------------------------
-	// The hash of paths/URLs
-	// HashItems []HashItem
-	Hash2Test []int
+	// The hash of paths/URLs, and everything else that is compiled from routes, is in a RouteTable.
+	// The current one is swapped in atomically, see routeTable.go
+	table     atomic.Value // Current *RouteTable
+	prevTable *RouteTable  // Table that was replaced, used by Rollback()
+	rejected  *RouteTable  // Table with errors that was not used because of StrictRoutes
+
+	names map[string]*ARoute // Routes by the name set with Name(), used by Get()
+
+	constraints    map[string]constraint // Named constraints for {name:int}, see AddConstraint
+	constraintLock sync.Mutex            //
 
 	// ------------------------------------------------------------------------------------------------------
-	// Info used during processing of a URL
-	CurUrl string                 // The current URL being processed.
-	Hash   [MaxSlashInUrl]int     // The set of hash keys in the current operation.
-	Slash  [MaxSlashInUrl + 1]int // Array of locaitons for the '/' in the url.  For /abc/def, it would be [ 0, 4, 8 ]
-	NSl    int                    // Number of slashes in the URL for /abc/def it would be 2
-	// allParam [MaxParams]Param       // The parameters for the current operation // PJS Sun Nov 15 13:02:59 MST 2015
-	AllParam Params // Slice that pints into allParam
-	UsePat   string // The used T::T pattern for matching - at URL time.
-	cRoute   string //
-
-	MaxSlash int // Maximum number of slashes found in any route
+	// Info used during processing of a URL is in a MatchState, one per request, see matchState.go
+	statePool sync.Pool // Pool of *MatchState
 
 	widgetBefore   []GoGoWidgetSet // Support for middleware (GoGoWidget)
 	widgetAfter    []GoGoWidgetSet
 	widgetHashNewM []GoGoWidgetSet
 
-	// ------------------------------------------------------------------------------------------------------
-	// Setup Info
-	routeData []RouteData // Raw routes - this is where the routes are kept before compile
-
 	// ------------------------------------------------------------------------------------------------------
 	// User settable handler called when no match is found.  Type: http.HandlerFunc.
 	// If not set then http.NotFound will be called.
 	NotFound http.HandlerFunc
 
+	// ------------------------------------------------------------------------------------------------------
+	// User settable handler called when a route that has been disabled with DisableRoute matches.
+	// If not set then the route is left out of the table and the match falls through to the next
+	// route that matches (or NotFound).  Set to ServiceUnavailable for a 503 maintenance response.
+	RouteDisabled http.HandlerFunc
+
+	// ------------------------------------------------------------------------------------------------------
+	// User settable handler called when the path has a route but not for the request method.
+	// The Allow header is set before it is called.  If not set then NotFound is called, and
+	// no time is spent looking for other methods.  Set to NotAllowed for a 405 response.
+	MethodNotAllowed http.HandlerFunc
+
+	// ------------------------------------------------------------------------------------------------------
+	// If true then OPTIONS requests that do not match a route are answered with an Allow header
+	// listing the methods that have a route for the path.  A route registered for OPTIONS is used
+	// first.  If OptionsHandler is set it is called after the header is set, otherwise 200 is returned.
+	HandleOPTIONS  bool
+	OptionsHandler http.HandlerFunc
+
+	// ------------------------------------------------------------------------------------------------------
+	// If true then a HEAD request that does not match a route is served by the GET route for the
+	// path.  The body is discarded, the headers and Content-Length are kept.
+	HandleHEAD bool
+
 	gen_hdlr int
 
 	// ------------------------------------------------------------------------------------------------------
@@ -118,10 +139,9 @@ type MuxRouter struct {
 	PanicHandler func(http.ResponseWriter, *http.Request, interface{})
 
 	// ------------------------------------------------------------------------------------------------------
-	HasBeenCompiled bool //	Flag, set to true when the routes are compiled.
-
-	LookupResults  []Collision2
-	nLookupResults int
+	HasBeenCompiled bool       //	Flag, set to true when the routes are compiled.
+	compileLock     sync.Mutex // Only one goroutine compiles or swaps the routes
+	dirty           int32      // Atomic, set when routes are added or changed, the next request compiles them
 
 	//www0 MyResponseWriter
 	//www  *MyResponseWriter
@@ -163,6 +183,17 @@ type ARoute struct {
 	QueryMatchMap  map[string]string      // Map constructed form pairs of DQueries
 	FileName       string                 // Line no && File name where this was defined
 	LineNo         int                    //
+	DNoCase        bool                   // Set by CaseInsensitive()
+	hostTpl        *hostTemplate          // Compiled DHost, DHostPort and DPort
+	hostPortTpl    *hostTemplate          //
+	portTpl        *hostTemplate          //
+	DMatchers      []MatcherFunc          // Set by MatcherFunc()
+	headerMatch    []valueMatch           // Compiled DHeaders
+	queryMatch     []valueMatch           // Compiled DQueries
+	disabled       bool                   // Set by DisableRoute
+	errors         []*RouteError          // Found when the route was registered
+	group          *ARoute                // Set for routes registered on a SubRouter
+	isGroup        bool                   // Set by Subrouter()
 }
 
 type RouteData struct {
@@ -173,6 +204,7 @@ type RouteData struct {
 	Ns          int             //
 	MatchIt     []Match         // Array of potential matches with regular expressions
 	MatchItRank MatchItRankType //
+	Segs        []RouteSegment  // Route from ParseRoute
 }
 
 type MatchItRankType uint32
@@ -283,15 +315,30 @@ func (r *MuxRouter) ListRoutes() []*ARoute {
 // NewRoute registers an empty route.
 func (r *MuxRouter) NewRoute() *ARoute {
 	fn, ln := LineFile(3)
-	route := &ARoute{parent: r, LineNo: ln, FileName: fn}
+	return r.newRoute(nil, fn, ln)
+}
+
+// Add a route, group is set for routes registered on a SubRouter.
+func (r *MuxRouter) newRoute(group *ARoute, fn string, ln int) *ARoute {
+	route := &ARoute{parent: r, group: group, LineNo: ln, FileName: fn}
 	route.DProtocal = make(map[string]bool)        // Set by Protocal() https == TLS on, http == no TLS, both is no-check(default)
 	route.DUser = make(map[string]interface{})     // Can be set by user to data needed in matches.
 	route.HeaderMatchMap = make(map[string]string) // Map constructed form pairs of DHeaders
 	route.QueryMatchMap = make(map[string]string)  // Map constructed form pairs of DQueries
+	r.compileLock.Lock()
 	r.routes = append(r.routes, route)
+	r.compileLock.Unlock()
+	route.changed()
 	return route
 }
 
+// Mark the router as needing a compile, the route has been added or changed.
+func (r *ARoute) changed() {
+	if r.parent != nil {
+		atomic.StoreInt32(&r.parent.dirty, 1)
+	}
+}
+
 // I am still working on this pair of functions!
 
 //// Handle registers a new route with a matcher for the URL path.
@@ -320,6 +367,14 @@ func (r *MuxRouter) HandleFunc(path string, f HandleFunc) *ARoute {
 func (r *ARoute) HandleFunc(path string, f HandleFunc) *ARoute {
 	r.DPath = path // Path pattern
 	r.DHandlerFunc = f
+	r.changed()
+	return r
+}
+
+// HandlerFunc sets the handler for the route without changing the path.
+func (r *ARoute) HandlerFunc(f HandleFunc) *ARoute {
+	r.DHandlerFunc = f
+	r.changed()
 	return r
 }
 
@@ -330,18 +385,22 @@ func (r *MuxRouter) Headers(pairs ...string) *ARoute {
 }
 
 // Headers registers a new route with a matcher for request header values.
+// The pairs are name, value.  A value of "" only checks that the header is present.
+// "{name}" matches any value and "{name:re}" a regular expression, the value is
+// put in the Params as name.
 func (r *ARoute) Headers(pairs ...string) *ARoute {
 	// fmt.Printf("Setting Header - bot\n")
 	r.DHeaders = append(r.DHeaders, pairs...)
+	r.changed()
 	return r
 }
 
 // Set/Append to list of valid host/ports for all routes by this router
 func (r *MuxRouter) HostPort_AllRoutes(hp ...string) *MuxRouter {
+	if r.AllHostPort == nil {
+		r.AllHostPort = make(map[string]int)
+	}
 	for _, v := range hp {
-		if hasReInString(v) {
-			fmt.Printf("Warning(20018): regular expressions are not supported in host/port.  This will be fixed in a week or two.\n")
-		}
 		r.AllHostPortFlag = true
 		r.AllHostPort[v] = hpn
 		hpn += 3
@@ -357,11 +416,11 @@ func (r *MuxRouter) Host(h string) *ARoute {
 }
 
 // Host registers a new route with a matcher for the URL host.
+// The host can be a template, {subdomain:[a-z]+}.example.com or *.example.com, the
+// variables are in the Params with From set to FromHost.
 func (r *ARoute) Host(h string) *ARoute {
-	if hasReInString(h) {
-		fmt.Printf("Warning(20018): regular expressions are not supported in host/port.  This will be fixed in a week or two.\n")
-	}
 	r.DHost = h
+	r.changed()
 	return r
 }
 
@@ -371,11 +430,10 @@ func (r *MuxRouter) HostPort(h string) *ARoute {
 }
 
 // Host:Port registers a new route with a matcher for the URL host and port.
+// This can be a template like Host().
 func (r *ARoute) HostPort(h string) *ARoute {
-	if hasReInString(h) {
-		fmt.Printf("Warning(20018): regular expressions are not supported in host/port.  This will be fixed in a week or two.\n")
-	}
 	r.DHostPort = h
+	r.changed()
 	return r
 }
 
@@ -386,7 +444,12 @@ func (r *MuxRouter) Name(n string) *ARoute {
 
 // Name sets the name for this route.  This is not use for matching the route.
 func (r *ARoute) Name(n string) *ARoute {
+	old := r.DName
 	r.DName = n
+	if r.parent != nil {
+		r.parent.setName(r, old)
+	}
+	r.changed()
 	return r
 }
 
@@ -400,6 +463,7 @@ func (r *MuxRouter) Id(n int) *ARoute {
 // route.  This is used in testing.
 func (r *ARoute) Id(n int) *ARoute {
 	r.DId = n
+	r.changed()
 	return r
 }
 
@@ -412,11 +476,10 @@ func (r *MuxRouter) Port(p string) *ARoute {
 // Port sets the port or this route.   This is a string like "80" or "8000"
 // xyzzy
 // xyzzy - ports are numbers ? validate!
+// This can be a template, {port:[0-9]+}.
 func (r *ARoute) Port(p string) *ARoute {
-	if hasReInString(p) {
-		fmt.Printf("Warning(20018): regular expressions are not supported in host/port.  This will be fixed in a week or two.\n")
-	}
 	r.DPort = p
+	r.changed()
 	return r
 }
 
@@ -427,22 +490,42 @@ func (r *MuxRouter) Protocal(p ...string) *ARoute {
 
 // Protocal sets the HTTP Protocal http/1.0, http/1.1, http/2.0
 func (r *ARoute) Protocal(p ...string) *ARoute {
-	if checkProtocal(p) {
+	if checkProtocal(r, p) {
 		for _, v := range p {
 			///*db*/ fmt.Printf("Setting ->%s<- to true\n", v)
 			r.DProtocal[v] = true
 		}
 	}
+	r.changed()
 	return r
 }
 
 // MatcherFunc registers a new route with a custom matcher function.
-// xyzzy
-/*
 func (r *MuxRouter) MatcherFunc(f MatcherFunc) *ARoute {
 	return r.NewRoute().MatcherFunc(f)
 }
-*/
+
+// Matcher registers a new route with a matcher, see HostMatcher, Or, All and Not.
+func (r *MuxRouter) Matcher(m Matcher) *ARoute {
+	return r.NewRoute().Matcher(m)
+}
+
+// MatcherFunc adds a custom matcher function to the route.  All of the functions on a
+// route have to return true for the route to match.  Each function sets the next of the
+// User0Match..User4Match rank bits, so a route with more functions is tried before the
+// same route with fewer.
+func (r *ARoute) MatcherFunc(f MatcherFunc) *ARoute {
+	r.DMatchers = append(r.DMatchers, f)
+	r.changed()
+	return r
+}
+
+// Matcher adds a matcher to the route, the same as MatcherFunc.  Errors from building
+// m are reported by Errors() for this route.
+func (r *ARoute) Matcher(m Matcher) *ARoute {
+	r.errors = append(r.errors, matcherErrors(m)...)
+	return r.MatcherFunc(m.Match)
+}
 
 // Methods registers a new route with a matcher for HTTP methods.
 func (r *MuxRouter) Methods(methods ...string) *ARoute {
@@ -451,9 +534,10 @@ func (r *MuxRouter) Methods(methods ...string) *ARoute {
 
 // Methods registers a new route with a matcher for HTTP methods.
 func (r *ARoute) Methods(methods ...string) *ARoute {
-	if checkMethods(methods) {
+	if checkMethods(r, methods) {
 		r.DMethods = append(r.DMethods, methods...)
 	}
+	r.changed()
 	return r
 }
 
@@ -464,9 +548,10 @@ func (r *MuxRouter) Schemes(schemes ...string) *ARoute {
 
 // Schemes registers a new route with a matcher for URL schemes.
 func (r *ARoute) Schemes(schemes ...string) *ARoute {
-	if checkScheme(schemes) {
+	if checkScheme(r, schemes) {
 		r.DSchemes = append(r.DSchemes, schemes...)
 	}
+	r.changed()
 	return r
 }
 
@@ -476,27 +561,39 @@ func (r *MuxRouter) PathPrefix(p string) *ARoute {
 }
 
 // PathPrefix registers a new route with a matcher for the URL path prefix.
+// With a path the prefix is added to the front of it.  With no path the route
+// matches every URL below the prefix and the rest of the URL is in the Params as
+// PathPrefixParam.  Longer prefixes are tried first.
+//
+//	r.PathPrefix("/static/").HandlerFunc(serveStatic)	// /static/css/a.css, rest=css/a.css
 func (r *ARoute) PathPrefix(p string) *ARoute {
 	r.DPathPrefix = p
+	r.changed()
 	return r
 }
 
 // Queries registers a new route with a matcher for URL query values.
-// xyzzy
+// A value of "" only checks that the name is present.  "{id}" or "{id:[0-9]+}"
+// put the value in the Params as "id", see Headers.  The ParseQueryParams widget
+// is not needed.
 func (r *MuxRouter) Queries(q ...string) *ARoute {
 	return r.NewRoute().Queries(q...)
 }
 func (r *ARoute) Queries(q ...string) *ARoute {
 	if len(q)%2 == 1 {
-		fmt.Printf("Query parameter is invalid.  Must be paris of name, value. Called From: %s\n", debug.LF(1))
+		r.addError(20018, "Query parameter is invalid.  Must be pairs of name, value", strings.Join(q, ","))
 	} else {
 		r.DQueries = append(r.DQueries, q...)
 	}
-	for _, p := range q {
-		if hasReInString(p) {
-			fmt.Printf("Warning(20018): regular expressions are not supported in host/port.  This will be fixed in a week or two.\n")
-		}
-	}
+	r.changed()
+	return r
+}
+
+// CaseInsensitive makes the words in this route match without regard to case.
+// Params keep the case from the URL.  See also CaseInsensitive in MuxRouter.
+func (r *ARoute) CaseInsensitive() *ARoute {
+	r.DNoCase = true
+	r.changed()
 	return r
 }
 
@@ -508,6 +605,7 @@ func (r *MuxRouter) Path(tpl string) *ARoute {
 // Path registers a new route with a matcher for the URL path.
 func (r *ARoute) Path(p string) *ARoute {
 	r.DPath = p
+	r.changed()
 	return r
 }
 
@@ -516,6 +614,7 @@ func (r *ARoute) Path(p string) *ARoute {
 // ----------------------------------------------------------------------------
 func (r *ARoute) AppendFileName(p string) *ARoute {
 	r.FileName += p
+	r.changed()
 	return r
 }
 
@@ -523,14 +622,21 @@ func (r *ARoute) AppendFileName(p string) *ARoute {
 // Context
 // ----------------------------------------------------------------------------
 
-// RouteMatch stores information about a matched route.
-/*
+// RouteMatch stores information about a matched route.  See Match.
 type RouteMatch struct {
-	Route   *Route
-	Handler http.Handler
-	Vars    map[string]string
+	Route   *ARoute    // The route that matched, as it was registered
+	Handler HandleFunc // Handler for the route - not called by Match
+	Params  *Params    // Params from the URL, from Match a copy that can be kept
+	UsePat  string     // The T::T pattern that was used to match
+	CType   colType    // Type of the Collision2 entry that matched, IsWord, SingleUrl, MultiUrl
 }
 
+// MatcherFunc is the function signature used by custom matchers, see also Matcher.  It is called while
+// the route is being matched, so rm.Params only has the values found before it.  It
+// points at the Params being built, values added to it are kept if the route matches.
+type MatcherFunc func(req *http.Request, rm *RouteMatch) bool
+
+/*
 type contextKey int
 
 const (
@@ -589,16 +695,16 @@ func cleanPath(p string) string {
 
 // Create default values for matching.   By default a non-specified route is
 // assued to be a GET request with any scheme (both https and http)
-func (r *MuxRouter) setDefaults() {
-	for i := 0; i < len(r.routes); i++ {
-		if r.routes[i].DId == 0 {
-			r.routes[i].DId = i
+func (t *RouteTable) setDefaults() {
+	for i := 0; i < len(t.routes); i++ {
+		if t.routes[i].DId == 0 {
+			t.routes[i].DId = i
 		}
-		if len(r.routes[i].DMethods) == 0 {
-			r.routes[i].DMethods = append(r.routes[i].DMethods, "GET")
+		if len(t.routes[i].DMethods) == 0 {
+			t.routes[i].DMethods = append(t.routes[i].DMethods, "GET")
 		}
-		if len(r.routes[i].DSchemes) == 0 {
-			r.routes[i].DSchemes = append(r.routes[i].DSchemes, "")
+		if len(t.routes[i].DSchemes) == 0 {
+			t.routes[i].DSchemes = append(t.routes[i].DSchemes, "")
 		}
 	}
 }
@@ -622,151 +728,223 @@ func isHttpHttps(s []string) (ignore bool, http bool, https bool) {
 	return
 }
 
-// Convert from the supplied routing information in r.routes to the sortable
+// Convert from the supplied routing information in t.routes to the sortable
 // routing information.
-func (r *MuxRouter) buildRoutingTable() {
-	for i, v := range r.routes {
-		for _, w := range r.routes[i].DMethods {
-			k := r.addRoute(w, v.DPathPrefix+v.DPath, v.DId, v.DHandlerFunc, i, v.FileName, v.LineNo)
-			if k >= 0 {
-
-				ignore, http, https := isHttpHttps(v.DSchemes)
-				if !ignore {
-					if https && !http {
-						r.setHTTPS_Only(k)
-					} else if http && !https {
-						r.setHTTP_Only(k)
+func (t *RouteTable) buildRoutingTable() {
+	for i, v := range t.routes {
+		for _, w := range t.routes[i].DMethods {
+			for _, p := range routePaths(v) {
+				k := t.addRoute(w, p, v.DId, v.DHandlerFunc, i, v.FileName, v.LineNo)
+				if k >= 0 {
+
+					ignore, http, https := isHttpHttps(v.DSchemes)
+					if !ignore {
+						if https && !http {
+							t.setHTTPS_Only(k)
+						} else if http && !https {
+							t.setHTTP_Only(k)
+						}
 					}
-				}
 
-				if v.DHostPort != "" {
-					r.setHostPort(k)
-				}
-				if v.DHost != "" {
-					r.setHost(k)
-				}
-				if v.DPort != "" {
-					r.setPort(k)
-				}
-				if len(v.DHeaders) > 0 {
-					// fmt.Printf("Setting DHeaders\n")
-					x, err := mapFromPairs(v.DHeaders...)
-					if err != nil {
-						fmt.Printf("Error(20012): %s FileName: %s LineNo: %d\n", err, v.FileName, v.LineNo)
-					} else {
-						v.HeaderMatchMap = x
-						r.setHeaderMatch(k)
+					if v.DHostPort != "" {
+						v.hostPortTpl = t.hostTemplate(v.DHostPort, v.FileName, v.LineNo)
+						t.setHostPort(k)
 					}
-				}
-				if len(v.DQueries) > 0 {
-					x, err := mapFromPairs(v.DQueries...)
-					if err != nil {
-						fmt.Printf("Error(20018): %s FileName: %s LineNo: %d\n", err, v.FileName, v.LineNo)
-					} else {
-						v.QueryMatchMap = x
-						r.setQueryMatch(k)
+					if v.DHost != "" {
+						v.hostTpl = t.hostTemplate(v.DHost, v.FileName, v.LineNo)
+						t.setHost(k)
+					}
+					if v.DPort != "" {
+						v.portTpl = t.hostTemplate(v.DPort, v.FileName, v.LineNo)
+						t.setPort(k)
+					}
+					if v.DHostPort == "" && v.DHost == "" && len(t.allHostPort) > 0 {
+						t.setAllHostPort(k)
+					}
+					if len(v.DHeaders) > 0 {
+						// fmt.Printf("Setting DHeaders\n")
+						x, err := mapFromPairs(v.DHeaders...)
+						if err != nil {
+							t.addError(newRouteError(20012, err.Error(), strings.Join(v.DHeaders, ","), v.FileName, v.LineNo))
+						} else {
+							v.HeaderMatchMap = x
+							var e *RouteError
+							v.headerMatch, e = compileValueMatch(v.DHeaders, true, v.FileName, v.LineNo)
+							t.addError(e)
+							t.setHeaderMatch(k)
+						}
+					}
+					if len(v.DQueries) > 0 {
+						x, err := mapFromPairs(v.DQueries...)
+						if err != nil {
+							t.addError(newRouteError(20018, err.Error(), strings.Join(v.DQueries, ","), v.FileName, v.LineNo))
+						} else {
+							v.QueryMatchMap = x
+							var e *RouteError
+							v.queryMatch, e = compileValueMatch(v.DQueries, false, v.FileName, v.LineNo)
+							t.addError(e)
+							t.setQueryMatch(k)
+						}
+					}
+					// func (r *MuxRouter) setProtocal(k int) {
+					if !IsMapStringBoolEmpty(v.DProtocal) {
+						t.setProtocal(k)
+					}
+					if len(v.DMatchers) > 0 {
+						t.setUserMatch(k, len(v.DMatchers))
 					}
-				}
-				// func (r *MuxRouter) setProtocal(k int) {
-				if !IsMapStringBoolEmpty(v.DProtocal) {
-					r.setProtocal(k)
 				}
 			}
 		}
 	}
 }
 
+// PathPrefixParam is the name of the param that has the rest of the path for a route
+// with a PathPrefix and no path.
+const PathPrefixParam = "rest"
+
+// The paths to add for a route.  A PathPrefix with no path matches every URL below the
+// prefix, "/static/" is "/static/*rest".  A prefix with no trailing '/' also matches the
+// prefix by itself, "/docs" is "/docs" and "/docs/*rest".
+func routePaths(v *ARoute) []string {
+	p := v.DPathPrefix
+	if p == "" || v.DPath != "" {
+		return []string{joinPath(p, v.DPath)} // PathPrefix("/api/").Path("/x")
+	}
+	if p[len(p)-1] == '/' {
+		return []string{p + "*" + PathPrefixParam}
+	}
+	return []string{p, p + "/*" + PathPrefixParam}
+}
+
 // ----------------------------------------------------------------------------
 // Perform the match of a header.
-func matchHeaderMatch(req *http.Request, r *MuxRouter, route_i int) bool {
-	return matchMap(r.routes[route_i].HeaderMatchMap, req.Header, true)
+func matchHeaderMatch(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool {
+	return matchValues(ms.rt.routes[route_i].headerMatch, req.Header, FromHeader, 'H', &ms.AllParam)
 }
 
 // Setup to match headers.
-func (r *MuxRouter) setHeaderMatch(k int) {
-	r.routeData[k].MatchIt = append(r.routeData[k].MatchIt, Match{MatchFunc: matchHeaderMatch})
-	r.routeData[k].MatchItRank |= HeaderMatch
+func (t *RouteTable) setHeaderMatch(k int) {
+	t.routeData[k].MatchIt = append(t.routeData[k].MatchIt, Match{MatchFunc: matchHeaderMatch})
+	t.routeData[k].MatchItRank |= HeaderMatch
 }
 
 // ----------------------------------------------------------------------------
-// Perform a match on the Query portion of the URL (Requires that the query
-// be parsed by the GoGoWidget.
-func matchQueryMatch(req *http.Request, r *MuxRouter, route_i int) bool {
-	return matchQueryMap(r.routes[route_i].QueryMatchMap, r.AllParam)
+// Perform a match on the Query portion of the URL.  The query is parsed once
+// per request, the ParseQueryParams widget is not needed.
+func matchQueryMatch(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool {
+	if ms.query == nil {
+		ms.query, _ = url.ParseQuery(req.URL.RawQuery)
+	}
+	return matchValues(ms.rt.routes[route_i].queryMatch, ms.query, FromParams, 'q', &ms.AllParam)
 }
 
 // Setup to match on query.
-func (r *MuxRouter) setQueryMatch(k int) {
-	r.routeData[k].MatchIt = append(r.routeData[k].MatchIt, Match{MatchFunc: matchQueryMatch})
-	r.routeData[k].MatchItRank |= QueryMatch
+func (t *RouteTable) setQueryMatch(k int) {
+	t.routeData[k].MatchIt = append(t.routeData[k].MatchIt, Match{MatchFunc: matchQueryMatch})
+	t.routeData[k].MatchItRank |= QueryMatch
 }
 
 // ----------------------------------------------------------------------------
-func matchTlsFunc(req *http.Request, r *MuxRouter, route_i int) bool {
+func matchTlsFunc(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool {
 	return req.TLS != nil
 }
-func matchNoTlsFunc(req *http.Request, r *MuxRouter, route_i int) bool {
+func matchNoTlsFunc(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool {
 	return req.TLS == nil
 }
-func (r *MuxRouter) setHTTPS_Only(k int) {
-	r.routeData[k].MatchIt = append(r.routeData[k].MatchIt, Match{MatchFunc: matchTlsFunc})
-	r.routeData[k].MatchItRank |= TLSMatch
+func (t *RouteTable) setHTTPS_Only(k int) {
+	t.routeData[k].MatchIt = append(t.routeData[k].MatchIt, Match{MatchFunc: matchTlsFunc})
+	t.routeData[k].MatchItRank |= TLSMatch
 }
-func (r *MuxRouter) setHTTP_Only(k int) {
-	r.routeData[k].MatchIt = append(r.routeData[k].MatchIt, Match{MatchFunc: matchNoTlsFunc})
-	r.routeData[k].MatchItRank |= TLSMatch
+func (t *RouteTable) setHTTP_Only(k int) {
+	t.routeData[k].MatchIt = append(t.routeData[k].MatchIt, Match{MatchFunc: matchNoTlsFunc})
+	t.routeData[k].MatchItRank |= TLSMatch
 }
 
 // ----------------------------------------------------------------------------
-func matchPortFunc(req *http.Request, r *MuxRouter, route_i int) bool {
-	// fmt.Printf("***************************** r.routes[%d].DPort ->%s<- v.s. %s\n", route_i, r.routes[route_i].DPort, req.Host)
-	colon := LastIndexOfChar(req.Host, ':')
-	// fmt.Printf("!! DPort ->%s<- vs. ->%s<-\n", r.routes[route_i].DPort, req.Host[colon+1:])
-	if colon != -1 {
-		return r.routes[route_i].DPort == req.Host[colon+1:]
-	} else {
-		return r.routes[route_i].DPort == "80"
+func matchPortFunc(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool {
+	// fmt.Printf("***************************** r.routes[%d].DPort ->%s<- v.s. %s\n", route_i, ms.rt.routes[route_i].DPort, req.Host)
+	_, port := splitHostPort(req.Host)
+	if port == "" {
+		port = "80"
 	}
+	return ms.rt.routes[route_i].portTpl.match(port, &ms.AllParam)
 }
-func (r *MuxRouter) setPort(k int) {
-	r.routeData[k].MatchIt = append(r.routeData[k].MatchIt, Match{MatchFunc: matchPortFunc})
-	r.routeData[k].MatchItRank |= PortMatch
+func (t *RouteTable) setPort(k int) {
+	t.routeData[k].MatchIt = append(t.routeData[k].MatchIt, Match{MatchFunc: matchPortFunc})
+	t.routeData[k].MatchItRank |= PortMatch
 }
 
 // ----------------------------------------------------------------------------
-func matchHostFunc(req *http.Request, r *MuxRouter, route_i int) bool {
-	// fmt.Printf("***************************** r.routes[%d].DHost ->%s<- v.s. %s\n", route_i, r.routes[route_i].DHost, req.Host)
-	colon := LastIndexOfChar(req.Host, ':')
-	if colon != -1 {
-		return r.routes[route_i].DHost == req.Host[:colon]
-	} else {
-		return false
-	}
+func matchHostFunc(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool {
+	// fmt.Printf("***************************** r.routes[%d].DHost ->%s<- v.s. %s\n", route_i, ms.rt.routes[route_i].DHost, req.Host)
+	host, _ := splitHostPort(req.Host)
+	return ms.rt.routes[route_i].hostTpl.match(host, &ms.AllParam)
+}
+func (t *RouteTable) setHost(k int) {
+	t.routeData[k].MatchIt = append(t.routeData[k].MatchIt, Match{MatchFunc: matchHostFunc})
+	t.routeData[k].MatchItRank |= HostMatch
 }
-func (r *MuxRouter) setHost(k int) {
-	r.routeData[k].MatchIt = append(r.routeData[k].MatchIt, Match{MatchFunc: matchHostFunc})
-	r.routeData[k].MatchItRank |= HostMatch
+
+// ----------------------------------------------------------------------------
+func matchHostPortFunc(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool {
+	// fmt.Printf("***************************** r.routes[%d].DHostPort ->%s<- v.s. %s\n", route_i, ms.rt.routes[route_i].DHostPort, req.Host)
+	return ms.rt.routes[route_i].hostPortTpl.match(req.Host, &ms.AllParam)
+}
+func (t *RouteTable) setHostPort(k int) {
+	t.routeData[k].MatchIt = append(t.routeData[k].MatchIt, Match{MatchFunc: matchHostPortFunc}) // route_i
+	t.routeData[k].MatchItRank |= PortHostMatch
 }
 
 // ----------------------------------------------------------------------------
-func matchHostPortFunc(req *http.Request, r *MuxRouter, route_i int) bool {
-	// fmt.Printf("***************************** r.routes[%d].DHostPort ->%s<- v.s. %s\n", route_i, r.routes[route_i].DHostPort, req.Host)
-	return r.routes[route_i].DHostPort == req.Host
+// Match one of the host/ports set with HostPort_AllRoutes, for routes that do not have their own.
+func matchAllHostPortFunc(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool {
+	for _, ht := range ms.rt.allHostPort {
+		if ht.match(req.Host, &ms.AllParam) {
+			return true
+		}
+	}
+	return false
 }
-func (r *MuxRouter) setHostPort(k int) {
-	r.routeData[k].MatchIt = append(r.routeData[k].MatchIt, Match{MatchFunc: matchHostPortFunc}) // route_i
-	r.routeData[k].MatchItRank |= PortHostMatch
+func (t *RouteTable) setAllHostPort(k int) {
+	t.routeData[k].MatchIt = append(t.routeData[k].MatchIt, Match{MatchFunc: matchAllHostPortFunc})
+	t.routeData[k].MatchItRank |= PortHostMatch
 }
 
 // ----------------------------------------------------------------------------
-func matchProtocalFunc(req *http.Request, r *MuxRouter, route_i int) bool {
-	///*db*/ fmt.Printf(":42: Checking ->%s<- for correct protocal = %v, %s\n", req.Proto, r.routes[route_i].DProtocal[req.Proto], debug.LF())
-	return r.routes[route_i].DProtocal[req.Proto]
+func matchProtocalFunc(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool {
+	///*db*/ fmt.Printf(":42: Checking ->%s<- for correct protocal = %v, %s\n", req.Proto, ms.rt.routes[route_i].DProtocal[req.Proto], debug.LF())
+	return ms.rt.routes[route_i].DProtocal[req.Proto]
 }
-func (r *MuxRouter) setProtocal(k int) {
+func (t *RouteTable) setProtocal(k int) {
 	///*db*/ fmt.Printf(":42:setProtocal called\n")
-	r.routeData[k].MatchIt = append(r.routeData[k].MatchIt, Match{MatchFunc: matchProtocalFunc}) // route_i
-	r.routeData[k].MatchItRank |= ProtocalMatch
+	t.routeData[k].MatchIt = append(t.routeData[k].MatchIt, Match{MatchFunc: matchProtocalFunc}) // route_i
+	t.routeData[k].MatchItRank |= ProtocalMatch
+}
+
+// ----------------------------------------------------------------------------
+// Call the functions from MatcherFunc.  The RouteMatch has the route and the
+// Params that have been found so far (host, headers, query), not the URL Params.
+// Values the functions add to rm.Params are kept.
+func matchUserFunc(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool {
+	rt := ms.rt.routes[route_i]
+	ms.rm.Route = ms.rt.from[route_i]
+	ms.rm.Handler = rt.DHandlerFunc
+	ms.rm.Params = &ms.AllParam // Not a copy, this is called for each request
+	ms.rm.UsePat = ms.UsePat
+	for _, f := range rt.DMatchers {
+		if !f(req, &ms.rm) {
+			return false
+		}
+	}
+	ms.AllParam.search_ready = false
+	return true
+}
+func (t *RouteTable) setUserMatch(k int, n int) {
+	t.routeData[k].MatchIt = append(t.routeData[k].MatchIt, Match{MatchFunc: matchUserFunc})
+	for i := 0; i < n && i < 5; i++ {
+		t.routeData[k].MatchItRank |= User0Match << uint(i)
+	}
 }
 
 // ----------------------------------------------------------------------------
@@ -778,6 +956,7 @@ type MyResponseWriter struct {
 	Status        int
 	ResponseBytes int64
 	w             http.ResponseWriter
+	discardBody   bool // HEAD served by a GET route, count the body but do not send it
 }
 
 func (m *MyResponseWriter) Header() http.Header {
@@ -786,7 +965,7 @@ func (m *MyResponseWriter) Header() http.Header {
 }
 
 func (m *MyResponseWriter) Write(p []byte) (written int, err error) {
-	if disableOutput {
+	if disableOutput || m.discardBody {
 		written = len(string(p))
 		m.ResponseBytes += int64(written)
 		return written, nil
@@ -798,9 +977,21 @@ func (m *MyResponseWriter) Write(p []byte) (written int, err error) {
 
 func (m *MyResponseWriter) WriteHeader(p int) {
 	m.Status = p
+	if m.discardBody { // Sent by finishHead, after the length of the body is known
+		return
+	}
 	m.w.WriteHeader(p)
 }
 
+// Send the header for a HEAD request that was served by a GET route.  Content-Length
+// is set from the discarded body if the handler did not set it.
+func (m *MyResponseWriter) finishHead() {
+	if m.ResponseBytes > 0 && m.w.Header().Get("Content-Length") == "" {
+		m.w.Header().Set("Content-Length", strconv.FormatInt(m.ResponseBytes, 10))
+	}
+	m.w.WriteHeader(m.Status)
+}
+
 // ----------------------------------------------------------------------------
 // ----------------------------------------------------------------------------
 
@@ -851,17 +1042,18 @@ const (
 	Dummy             = 1 << iota
 )
 
-type MatchFunc func(req *http.Request, r *MuxRouter, route_i int) bool
+type MatchFunc func(req *http.Request, r *MuxRouter, ms *MatchState, route_i int) bool
 
 type Match struct {
 	MatchFunc MatchFunc
 }
 
 type Re struct {
-	Pos  int
-	Re   string
-	cRe  *regexp.Regexp
-	Name string
+	Pos   int
+	Re    string
+	cRe   *regexp.Regexp
+	Name  string
+	Names []string // For a SegMixed, the params that are groups in cRe
 }
 
 type ReList struct {
@@ -886,6 +1078,7 @@ type Collision2 struct {
 	LineNo     int        // Location this was created
 	FileName   string     // Location this was created
 	HasRe      []ReList   // Set of RE that is required to match this Collision2
+	ReSet      []Re       // The RE that matched, set on the Collision2 returned by LookupUrlViaHash2
 	MatchIt    []Match    // If additional matching criteria are used
 	route_i    int
 	Multi      map[string]Collision2 // if (cType&MultiUrl)!=0, then use string to disambiguate collisions
@@ -904,9 +1097,9 @@ type Collision2 struct {
 // This function is intended for bulk loading and to allow the usage of less
 // frequently used, non-standardized or custom methods (e.g. for internal
 // communication with a proxy).
-func (r *MuxRouter) addRoute(Method string, Route string, hdlr int, fx HandleFunc, pos int, fn string, ln int) int {
-	if Route[0] != '/' {
-		fmt.Printf("Error(20002): Path should begin with '/', passed %s, File:%s LinLineNo:%d\n", Route, fn, ln)
+func (t *RouteTable) addRoute(Method string, Route string, hdlr int, fx HandleFunc, pos int, fn string, ln int) int {
+	if Route == "" || Route[0] != '/' {
+		t.addError(newRouteError(20002, "Path should begin with '/'", Route, fn, ln))
 		//if oneSlash {
 		//	fmt.Printf("%s\n", debug.LF(1))
 		//	fmt.Printf("%s\n", debug.LF(2))
@@ -916,32 +1109,121 @@ func (r *MuxRouter) addRoute(Method string, Route string, hdlr int, fx HandleFun
 		return -1
 	}
 	if !validMethod[Method] {
-		fmt.Printf("Error(20003): Method invalid, should be one of: GET, POST, PUT, PATCH, OPTIONS, HEAD, CONNECT, TRACE or DELETE, instead got %s, File:%s LineNo:%d\n", Method, fn, ln)
+		t.addError(newRouteError(20003, "Method invalid, should be one of: GET, POST, PUT, PATCH, OPTIONS, HEAD, CONNECT, TRACE or DELETE", Method, fn, ln))
+		return -1
+	}
+	segs, err := ParseRoute(Route)
+	if err != nil {
+		t.addError(newRouteError(20043, err.Error(), Route, fn, ln))
 		return -1
 	}
 
-	k := len(r.routeData)
+	k := len(t.routeData)
 
-	r.routeData = append(r.routeData, RouteData{
+	t.routeData = append(t.routeData, RouteData{
 		Method: Method,
 		Route:  Route,
 		Hdlr:   hdlr,
 		NFxNo:  pos,
+		Segs:   segs,
 	})
 
 	return k
 }
 
-func (r *MuxRouter) CompileRoutes() {
+// CompileRoutes builds the routing table from the routes.  This is done on the first
+// request and again on the first request after a route is added or changed, so it is
+// only necessary to call it to build the table ahead of time, and to check the routes.
+// The error is a RouteErrors with all of the problems found.  Conflicts between routes,
+// Error(20042), are only returned with StrictRoutes.  The table is used even if there
+// are errors.  A route with a bad path or method is left out of it, for the other
+// errors the route is kept, see RouteError for what is done with the value.  With
+// StrictRoutes a table with errors is not used, the table from before stays in use,
+// or if there is none no routes are served.
+//
+//	if err := r.CompileRoutes(); err != nil {
+//		log.Fatal(err)
+//	}
+func (r *MuxRouter) CompileRoutes() error {
+	r.compileLock.Lock()
+	defer r.compileLock.Unlock()
+	r.compileLocked()
+	var errs RouteErrors
+	for _, e := range r.checkedTable().errors {
+		if e.Code != 20042 || r.StrictRoutes {
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
 
-	if r.HasBeenCompiled {
+// Compile, with compileLock already held.
+func (r *MuxRouter) compileLocked() {
+	if atomic.LoadInt32(&r.dirty) == 0 && r.RouteTable() != nil {
+		if r.rejected != nil && !r.StrictRoutes { // StrictRoutes was turned off
+			r.table.Store(r.rejected)
+			r.rejected = nil
+		}
 		return
 	}
-	r.HasBeenCompiled = true // Mark that the compilation has taken place.
+	atomic.StoreInt32(&r.dirty, 0) // Cleared first, a route changed during the build will set it again.
+	r.HasBeenCompiled = true       // Mark that the compilation has taken place.
+
+	t := r.BuildRouteTable(r.routes)
+	r.rejected = nil
+	if r.StrictRoutes && len(t.errors) > 0 {
+		r.rejected = t
+		if r.RouteTable() == nil {
+			r.table.Store(r.BuildRouteTable(nil)) // Nothing is served until the routes are fixed
+		}
+		return
+	}
+	r.table.Store(t)
+}
+
+// The table for a request, the routes are compiled first if they have changed.  The
+// first compile clears dirty before it stores the table, so a request that comes in
+// then waits for it.
+func (r *MuxRouter) servingTable() *RouteTable {
+	if atomic.LoadInt32(&r.dirty) != 0 {
+		r.CompileRoutes()
+	}
+	t := r.RouteTable()
+	if t == nil {
+		r.CompileRoutes()
+		t = r.RouteTable()
+	}
+	return t
+}
+
+// The table that was last compiled, it is not in use if it was rejected.  The caller
+// has the compileLock.
+func (r *MuxRouter) checkedTable() *RouteTable {
+	if r.rejected != nil {
+		return r.rejected
+	}
+	return r.RouteTable()
+}
+
+// BuildRouteTable compiles a set of routes into a new RouteTable.  The table that is
+// in use is not changed so this can be run in the background while requests are
+// served.  Use SwapRouteTable to put the new table into use.
+func (r *MuxRouter) BuildRouteTable(routes []*ARoute) *RouteTable {
+
+	t := newRouteTable(routes)
+	t.strictSlash = r.StrictSlash
+	t.inheritGroups()
+	t.disableRoutes(r.RouteDisabled)
+	for hp := range r.AllHostPort {
+		t.allHostPort = append(t.allHostPort, t.hostTemplate(hp, "HostPort_AllRoutes", 0))
+	}
 
-	r.setDefaults()
-	r.buildRoutingTable()
-	r.calcNumSlash() // Use this to find over MaxSlashInUrl of slashes and report error/warn.
+	t.setDefaults()
+	t.buildRoutingTable()
+	t.calcNumSlash() // Use this to find over MaxSlashInUrl of slashes and report error/warn.
 
 	// -------------------------------------------------------------------------------------------------
 	sf_NumSlash_Desc := func(c1, c2 *RouteData) bool {
@@ -959,23 +1241,33 @@ func (r *MuxRouter) CompileRoutes() {
 	sf_MatchFuncs := func(c1, c2 *RouteData) bool {
 		return c1.MatchItRank > c2.MatchItRank
 	}
+	sf_Registered := func(c1, c2 *RouteData) bool { // Same everything, try them in the order they were registered
+		return c1.NFxNo < c2.NFxNo
+	}
 	// -------------------------------------------------------------------------------------------------
 
-	OrderedBy(sf_MethodHash, sf_NumSlash_Desc, sf_Length_Desc, sf_Text, sf_MatchFuncs).Sort(r.routeData)
+	OrderedBy(sf_MethodHash, sf_NumSlash_Desc, sf_Length_Desc, sf_Text, sf_MatchFuncs, sf_Registered).Sort(t.routeData)
+	t.findConflicts(r)
 
-	///*db*/ r.DumpRouteData("After Sort")
+	///*db*/ t.DumpRouteData("After Sort")
 
-	for _, v := range r.routeData {
-		fx := r.routes[v.NFxNo].DHandlerFunc
-		FileName := r.routes[v.NFxNo].FileName
-		LineNo := r.routes[v.NFxNo].LineNo
-		cleanRoute, names := r.addPatT__T(v.Route, v.Hdlr, fx, FileName, LineNo)
+	ms := NewMatchState() // Scratch state for splitting the routes
+	ms.rt = t
+	for _, v := range t.routeData {
+		fx := t.routes[v.NFxNo].DHandlerFunc
+		FileName := t.routes[v.NFxNo].FileName
+		LineNo := t.routes[v.NFxNo].LineNo
+		ms.noCase = r.CaseInsensitive || t.routes[v.NFxNo].DNoCase
+		t.noCase = t.noCase || ms.noCase
+		cleanRoute, names := r.addPatT__T(ms, v.Route, v.Segs, v.Hdlr, fx, FileName, LineNo)
 		ns := numChar(v.Route, '/')
-		r.addHash2Map(v.Method, v.Route, cleanRoute, v.Hdlr, fx, names, v.MatchIt, ns, v.NFxNo, FileName, LineNo) // AddToM
+		r.addHash2Map(ms, v.Method, v.Route, v.Segs, cleanRoute, v.Hdlr, fx, names, v.MatchIt, ns, v.NFxNo, FileName, LineNo) // AddToM
 	}
 
-	r.addStarPat()
-	r.sortPat()
+	t.addStarPat()
+	t.sortPat()
+	t.setMethods()
+	return t
 }
 
 // -------------------------------------------------------------------------------------------------
@@ -990,10 +1282,12 @@ func (r *MuxRouter) CompileRoutes() {
 	Frequencey in PatOcc, 			Hi .. Lo
 
 
-This is dependent on having r.MaxSlash set properly.   That is set in calcNumSlash.
+This is dependent on having t.MaxSlash set properly.   That is set in calcNumSlash.
+The '*' patterns are copied to every longer length by addStarPat, so sorting on length puts
+the longer prefix first, "TT*" for /static/css/*rest before "T*" for /static/*rest.
 
 */
-func (r *MuxRouter) sortPat() {
+func (t *RouteTable) sortPat() {
 	var CurPatOcc map[string]int
 	sp_Length_Desc := func(c1, c2 *UrlAPat) bool {
 		return len(c1.Pat) > len(c2.Pat)
@@ -1007,72 +1301,106 @@ func (r *MuxRouter) sortPat() {
 	sp_Text := func(c1, c2 *UrlAPat) bool {
 		return c1.Pat < c2.Pat
 	}
-	for i := 0; i < minInt(MaxSlashInUrl, r.MaxSlash+1); i++ {
-		if nMatch[i].PatList != nil && len(nMatch[i].PatList) > 1 {
-			CurPatOcc = nMatch[i].PatOcc
+	for i := 0; i <= minInt(MaxSlashInUrl-1, t.MaxSlash+1); i++ {
+		if t.nMatch[i].PatList != nil && len(t.nMatch[i].PatList) > 1 {
+			CurPatOcc = t.nMatch[i].PatOcc
 			// fmt.Printf("sortPat: (before) nMatch[%d]=%s\n", i, debug.SVarI(nMatch[i]))
-			OrderedByPat(sp_Length_Desc, sp_DF, sp_Frequency, sp_Text).Sort(nMatch[i].PatList)
+			OrderedByPat(sp_Length_Desc, sp_DF, sp_Frequency, sp_Text).Sort(t.nMatch[i].PatList)
 			// fmt.Printf("sortPat: (after) nMatch[%d]=%s\n", i, debug.SVarI(nMatch[i]))
 		}
 	}
 }
 
 // -------------------------------------------------------------------------------------------------
-// Extract arguments from the URL.
-func (r *MuxRouter) GetArgs3(Url string, _ string, names []string, _ int) {
+// Extract arguments from the URL.  Url has to be ms.CurUrl, the path as it was cleaned by
+// SplitOnSlash3, ms.Slash has the offsets of the words in it.
+func (r *MuxRouter) GetArgs3(ms *MatchState, Url string, reSet []Re, names []string, _ int) {
 	k := 0
-	// db("GetArgs3", "names=%s r.UsePat=%s %s\n", debug.SVar(names), r.UsePat, debug.LF())
-	for i, v := range r.UsePat {
+	unescape := func(s string) string { return s }
+	if r.UseRawPath { // Routed on the escaped path, so the values need to be unescaped
+		unescape = unescapeParam
+	}
+	// db("GetArgs3", "names=%s ms.UsePat=%s %s\n", debug.SVar(names), ms.UsePat, debug.LF())
+	for i, v := range ms.UsePat {
 		// db("GetArgs3","k=%d\n", k)
 		if i < MaxSlashInUrl-1 {
 			vv := ""
 			if v == ':' {
-				if r.Slash[i]+1 < len(Url) && r.Slash[i+1] <= len(Url) {
-					vv = Url[r.Slash[i]+1 : r.Slash[i+1]]
+				if ms.Slash[i]+1 < len(Url) && ms.Slash[i+1] <= len(Url) {
+					vv = Url[ms.Slash[i]+1 : ms.Slash[i+1]]
 				}
-				AddValueToParams(names[k], vv, ':', FromURL, &r.AllParam)
+				AddValueToParams(names[k], unescape(vv), ':', FromURL, &ms.AllParam)
 				k++
 			} else if v == '{' {
-				if r.Slash[i]+1 < len(Url) && r.Slash[i+1] <= len(Url) {
-					vv = Url[r.Slash[i]+1 : r.Slash[i+1]]
+				if ms.Slash[i]+1 < len(Url) && ms.Slash[i+1] <= len(Url) {
+					vv = Url[ms.Slash[i]+1 : ms.Slash[i+1]]
 				}
-				AddValueToParams(names[k], vv, '{', FromURL, &r.AllParam)
+				if x := mixedAt(reSet, i); x != nil { // More than one param in the word
+					sm := x.cRe.FindStringSubmatch(vv)
+					for _, name := range x.Names {
+						if sm != nil {
+							vv = sm[x.cRe.SubexpIndex(name)]
+						}
+						AddValueToParams(name, unescape(vv), '{', FromURL, &ms.AllParam)
+						k++
+					}
+					continue
+				}
+				AddValueToParams(names[k], unescape(vv), '{', FromURL, &ms.AllParam)
 				k++
 			} else if v == '*' {
-				if r.Slash[i]+1 < len(Url) {
-					vv = Url[r.Slash[i]+1:]
+				if ms.Slash[i]+1 < len(Url) {
+					vv = Url[ms.Slash[i]+1:]
 				}
-				AddValueToParams(names[k], vv, '{', FromURL, &r.AllParam)
+				AddValueToParams(names[k], unescape(vv), '{', FromURL, &ms.AllParam)
 				k++
 			}
 		}
 	}
 }
 
-// Post process nMatch adding all of the "*" patterns where they need to be during LookupUrlViaHash2.
+// The RE for a SegMixed at word i, nil if there is none.
+func mixedAt(reSet []Re, i int) *Re {
+	for k := range reSet {
+		if reSet[k].Pos == i && reSet[k].Names != nil {
+			return &reSet[k]
+		}
+	}
+	return nil
+}
+
+// Unescape a value from the escaped path, if it is not valid leave it as it is.
+func unescapeParam(s string) string {
+	if v, err := url.PathUnescape(s); err == nil {
+		return v
+	}
+	return s
+}
+
+// Post process r.nMatch adding all of the "*" patterns where they need to be during LookupUrlViaHash2.
 //
 // for the longest pattern with a star
 //    for each pattern that is longer than that up to min(MaxSlashInUrl,r.MasSlash+1)
 //		Add that pattern (the * one) to the set of the others.
 //
-func (r *MuxRouter) addStarPat() {
+func (t *RouteTable) addStarPat() {
 	// fmt.Printf("nMatch=%s\n", debug.SVarI(nMatch))
-	for i := minInt(MaxSlashInUrl-1, r.MaxSlash+1); i > 0; i-- { // nothing at 0 so skip it.
+	for i := minInt(MaxSlashInUrl-1, t.MaxSlash+1); i > 0; i-- { // nothing at 0 so skip it.
 		// fmt.Printf("i=%d\n", i)
-		if nMatch[i].PatList != nil {
+		if t.nMatch[i].PatList != nil {
 			// fmt.Printf("Star is not nil\n")
-			for ii := 0; ii < len(nMatch[i].PatList); ii++ {
+			for ii := 0; ii < len(t.nMatch[i].PatList); ii++ {
 				// fmt.Printf("ii=%d\n", ii)
-				if nMatch[i].PatList[ii].Star {
-					mm := minInt(MaxSlashInUrl, r.MaxSlash+1)
-					for j := i + 1; j < mm; j++ {
+				if t.nMatch[i].PatList[ii].Star {
+					mm := minInt(MaxSlashInUrl-1, t.MaxSlash+1) // Longer URLs are looked up with NSl == mm
+					for j := i + 1; j <= mm; j++ {
 						// do add
-						p := nMatch[i].PatList[ii]
-						nMatch[j].PatList = append(nMatch[j].PatList, p)
-						if nMatch[j].PatOcc == nil {
-							nMatch[j].PatOcc = make(map[string]int)
+						p := t.nMatch[i].PatList[ii]
+						t.nMatch[j].PatList = append(t.nMatch[j].PatList, p)
+						if t.nMatch[j].PatOcc == nil {
+							t.nMatch[j].PatOcc = make(map[string]int)
 						}
-						nMatch[j].PatOcc[p.Pat] = 1
+						t.nMatch[j].PatOcc[p.Pat] = 1
 					}
 				}
 			}
@@ -1081,7 +1409,7 @@ func (r *MuxRouter) addStarPat() {
 }
 
 // Return true if the sting 'p' has a '*' in it.
-// Possible Improvement: this should be a better scanner - this, just look for '*' character is probably buggy.
+// 'p' is the T:* pattern from addPatT__T, a '*' is only there for a *name.
 func hasStar(p string) bool {
 	// func numChar(s string, c rune) (rv int) {
 	// fmt.Printf("p ->%s<- numChcar=%d\n", p, numChar(p, '*'))
@@ -1091,24 +1419,24 @@ func hasStar(p string) bool {
 	return false
 }
 
-// Add a pattern to nMatch - check to see if it is already there.
+// Add a pattern to r.nMatch - check to see if it is already there.
 // Possible Improvement - inefficient/slow but it works.
-func addPat2(NSl int, p string, FileName string, LineNo int) {
+func (t *RouteTable) addPat2(NSl int, p string, FileName string, LineNo int) {
 	f := false
 	// fmt.Printf("NSl=%d ->%s<- %s\n", NSl, p, debug.LF())
-	for _, v := range nMatch[NSl].PatList {
+	for _, v := range t.nMatch[NSl].PatList {
 		if v.Pat == p {
 			f = true
 			break
 		}
 	}
 	if !f {
-		nMatch[NSl].PatList = append(nMatch[NSl].PatList, UrlAPat{Pat: p, Star: hasStar(p)})
+		t.nMatch[NSl].PatList = append(t.nMatch[NSl].PatList, UrlAPat{Pat: p, Star: hasStar(p)})
 	}
-	if nMatch[NSl].PatOcc == nil {
-		nMatch[NSl].PatOcc = make(map[string]int)
+	if t.nMatch[NSl].PatOcc == nil {
+		t.nMatch[NSl].PatOcc = make(map[string]int)
 	}
-	nMatch[NSl].PatOcc[p]++
+	t.nMatch[NSl].PatOcc[p]++
 }
 
 // Count the number of characters 'c' in the string 's', return that value.
@@ -1122,26 +1450,20 @@ func numChar(s string, c rune) (rv int) {
 	return
 }
 
-func hasReInString(s string) bool {
-	a := numChar(s, '{')
-	b := numChar(s, '}')
-	return a > 0 && b > 0
-}
-
 // Iterate over the set of routes and calculate the number of '/' in each route.
-func (r *MuxRouter) calcNumSlash() {
-	for i, v := range r.routeData {
+func (t *RouteTable) calcNumSlash() {
+	for i, v := range t.routeData {
 		ns := numChar(v.Route, '/')
-		r.routeData[i].Ns = ns
+		t.routeData[i].Ns = ns
 		// fmt.Printf("ns=%2d %s\n", ns, v.Route)
 	}
-	r.MaxSlash = 1
-	for _, v := range r.routeData {
-		if r.MaxSlash < v.Ns {
-			r.MaxSlash = v.Ns
+	t.MaxSlash = 1
+	for _, v := range t.routeData {
+		if t.MaxSlash < v.Ns {
+			t.MaxSlash = v.Ns
 		}
 	}
-	// fmt.Printf("MaxSlash=%d\n", r.MaxSlash)
+	// fmt.Printf("MaxSlash=%d\n", t.MaxSlash)
 }
 
 // -------------------------------------------------------------------------------------------------
@@ -1209,57 +1531,45 @@ func (ms *multiSorter) Less(i, j int) bool {
 // Build the route pattern table.  A route of /abc/:def/ghi will become T:T for the fixed tokens and return
 // the string /abc/:/ghi for a matching patter for colision resolution.   The T:T patterns are stored by
 // addPat2().
-func (r *MuxRouter) addPatT__T(Route string, hdlr int, fx HandleFunc, FileName string, LineNo int) (ss string, names []string) {
-	i, k := 0, 0
+func (r *MuxRouter) addPatT__T(ms *MatchState, Route string, segs []RouteSegment, hdlr int, fx HandleFunc, FileName string, LineNo int) (ss string, names []string) {
 	//if oneSlash {
-	//	/*db*/ fmt.Printf("Route:%s, NSl=%d r.Slash=%s\n", Route, r.NSl, debug.SVar(r.Slash[:r.NSl+1]))
+	//	/*db*/ fmt.Printf("Route:%s, NSl=%d ms.Slash=%s\n", Route, ms.NSl, debug.SVar(ms.Slash[:ms.NSl+1]))
 	//}
 	pp := ""
-	r.SplitOnSlash3(1, Route, false)
+	r.SplitOnSlash3(ms, 1, segsPath(segs), false) // Without the '\' escapes
 	if Route == "/" {
 		ss += "/"
 		pp += "T"
 	} else {
-		for i = 0; i < r.NSl; i++ {
-			if r.Slash[i]+1 >= len(Route) {
-			} else if r.CurUrl[r.Slash[i]+1] == ':' {
+	Loop:
+		for i := 0; i < ms.NSl && i < len(segs); i++ {
+			s := segs[i]
+			switch s.Kind {
+			case SegParam, SegVar: // {name} is the same as :name
 				ss += "/:"
 				pp += ":"
-				names = append(names, r.CurUrl[r.Slash[i]+2:r.Slash[i+1]])
-				k++
-			} else if r.CurUrl[r.Slash[i]+1] == '*' {
+				names = append(names, s.Name)
+			case SegCatchAll:
 				ss += "/*"
 				pp += "*"
-				names = append(names, r.CurUrl[r.Slash[i]+2:r.Slash[i+1]])
-				k++
-				break
-			} else if r.CurUrl[r.Slash[i]+1] == '{' {
-				name, re, valid, convertToColon := parseReFromToken3(Route[r.Slash[i]+1 : r.Slash[i+1]])
-				names = append(names, name)
-				_, _, _, _ = name, re, valid, convertToColon
-				if convertToColon {
-					ss += "/:"
-					pp += ":"
-					k++
-					//if dbHash2 {
-					//	fmt.Printf(" ss=%s after : {nanme} / convertToColon, name=%s re=%s valid=%v \n", ss, name, re, valid)
-					//}
+				names = append(names, s.Name)
+				break Loop
+			case SegRegex, SegMixed:
+				ss += "/{"
+				pp += "{"
+				names = append(names, segNames(s)...)
+			default:
+				if ms.noCase {
+					ss += "/" + strings.ToLower(s.Text)
 				} else {
-					ss += "/{"
-					pp += "{"
-					k++
-					//if dbHash2 {
-					//	fmt.Printf(" ss=%s after {name:re}\n", ss)
-					//}
+					ss += "/" + s.Text // Trailing '/' is an empty word
 				}
-			} else {
-				ss += "/" + r.CurUrl[r.Slash[i]+1:r.Slash[i+1]]
 				pp += "T"
 			}
 		}
 	}
-	addPat2(r.NSl, pp, FileName, LineNo)
-	r.UsePat = pp
+	ms.rt.addPat2(ms.NSl, pp, FileName, LineNo)
+	ms.UsePat = pp
 	// ss = pp
 
 	//if dbHash2 {
@@ -1287,18 +1597,21 @@ func (r *MuxRouter) FixBadUrl(Url string) (rv string, fixed bool) {
 		}
 	}
 	// fmt.Printf("Trailing Slash: nUrl ->%s<- Url ->%s<-\n", nUrl, Url)
+	if strings.HasSuffix(Url, "/") && !strings.HasSuffix(nUrl, "/") { // Keep the trailing '/', /a//b/ is /a/b/
+		nUrl += "/"
+	}
 	if nUrl != Url {
-		r.CurUrl = nUrl
 		rv = nUrl
 		fixed = true
 	}
 	return
 }
 
-func (r *MuxRouter) addHash2Map(Method string, Route string, cleanRoute string, hdlr int, fx HandleFunc, names []string, AddToM []Match, ns int, NFxNo int, FileName string, LineNo int) {
+func (r *MuxRouter) addHash2Map(ms *MatchState, Method string, Route string, segs []RouteSegment, cleanRoute string, hdlr int, fx HandleFunc, names []string, AddToM []Match, ns int, NFxNo int, FileName string, LineNo int) {
 	//if dbMatch2 {
 	//	fmt.Printf("\naddHash2Map: len(AddToM) = %d %s\n", len(AddToM), debug.LF())
 	//}
+	t := ms.rt // The table being built
 	var i int
 	var ss int
 	ss = 0
@@ -1312,73 +1625,70 @@ func (r *MuxRouter) addHash2Map(Method string, Route string, cleanRoute string,
 	// m := ((int(Method[0]) + (int(Method[1]) << 1)) + AddToM) ^ (ns << 2)
 	m := MethodToCode(Method, 0)
 	// fmt.Printf("m=%d\n", m)
-	r.SplitOnSlash3(m, Route, false)
+	r.SplitOnSlash3(ms, m, segsPath(segs), false)
 	if optionEarlyExit {
-		hh := (r.Hash[0] ^ m) & bitMask
+		hh := (ms.Hash[0] ^ m) & bitMask
 		// fmt.Printf("hh=%d bitMask=%x\n", m, bitMask)
-		if r.Hash2Test[hh] == 0 {
-			r.Hash2Test[hh] = r.nLookupResults
-			r.LookupResults = append(r.LookupResults, Collision2{cType: IsWord})
-			r.nLookupResults++
+		if t.Hash2Test[hh] == 0 {
+			t.Hash2Test[hh] = t.nLookupResults
+			t.LookupResults = append(t.LookupResults, Collision2{cType: IsWord})
+			t.nLookupResults++
+		}
+		if segs[0].Kind != SegLiteral { // /:name can not be found by its first word either
+			t.setParamFirst(m, ms.NSl, segs)
 		}
 	}
 	//if dbHash2 {
-	//	fmt.Printf("After SplitOnSlash3 Orig:->%s<- Fixed:->%s<-\n r.Hash=%s r.Slash=%s r.NSl=%d\n", Route, r.CurUrl, debug.SVar(r.Hash[0:r.NSl]), debug.SVar(r.Slash[0:r.NSl+1]), r.NSl)
+	//	fmt.Printf("After SplitOnSlash3 Orig:->%s<- Fixed:->%s<-\n r.Hash=%s ms.Slash=%s ms.NSl=%d\n", Route, ms.CurUrl, debug.SVar(ms.Hash[0:ms.NSl]), debug.SVar(ms.Slash[0:ms.NSl+1]), ms.NSl)
 	//}
 	haveRealRe := false
-	for i = 0; i < r.NSl; i++ {
-		//if dbHash2 {
-		//	fmt.Printf("i=%d ->%c<-, ->%s<-", i, Route[r.Slash[i]+1], Route[r.Slash[i]+1:r.Slash[i+1]])
-		//}
-		if r.Slash[i]+1 >= len(Route) {
-			//if dbHash2 {
-			//	fmt.Printf("At (Added to code at this point) %s\n", debug.LF())
-			//}
-			ss = ss ^ r.Hash[i]
-		} else if Route[r.Slash[i]+1] == ':' {
+	hasWord := false
+Loop:
+	for i = 0; i < ms.NSl && i < len(segs); i++ {
+		s := segs[i]
+		switch s.Kind {
+		case SegParam, SegVar:
 			ss += 153
 			pp += ":"
-			reNames = append(reNames, Route[r.Slash[i]+2:r.Slash[i+1]])
-			//if dbHash2 {
-			//	fmt.Printf(" ss=%d after : 153\n", ss)
-			//}
-		} else if Route[r.Slash[i]+1] == '*' {
+			reNames = append(reNames, s.Name)
+		case SegCatchAll:
 			ss += 51
 			pp += "*"
-			//if dbHash2 {
-			//	fmt.Printf(" ss=%d after * 51\n", ss)
-			//}
-			reNames = append(reNames, Route[r.Slash[i]+2:r.Slash[i+1]])
-			break
-		} else if Route[r.Slash[i]+1] == '{' {
-			name, re, valid, convertToColon := parseReFromToken3(Route[r.Slash[i]+1 : r.Slash[i+1]])
-			_, _, _, _ = name, re, valid, convertToColon
-			if convertToColon {
-				ss += 153
-				pp += ":"
-				reNames = append(reNames, name)
-				//if dbHash2 {
-				//	fmt.Printf(" ss=%d after : 153 / convertToColon, name=%s re=%s valid=%v \n", ss, name, re, valid)
-				//}
-			} else {
-				haveRealRe = true
-				ss += 211
-				pp += "{"
-				aRe := regexp.MustCompile(re)
-				tmpRe = append(tmpRe, Re{Pos: i, Re: re, Name: name, cRe: aRe})
-				reNames = append(reNames, name)
-				//if dbHash2 {
-				//	fmt.Printf(" ss=%d after { 211\n", ss)
-				//}
+			reNames = append(reNames, s.Name)
+			break Loop
+		case SegRegex:
+			haveRealRe = true
+			ss += 211
+			pp += "{"
+			aRe, err := r.segmentRe(s)
+			if err != nil { // A bad regular expression, the route is left out
+				t.addError(newRouteError(20043, err.Error(), Route, FileName, LineNo))
+				aRe = neverMatch
+			}
+			tmpRe = append(tmpRe, Re{Pos: i, Re: s.Re, Name: s.Name, cRe: aRe})
+			reNames = append(reNames, s.Name)
+		case SegMixed: // :name.:ext - one RE for the word with a group for each param
+			haveRealRe = true
+			ss += 211
+			pp += "{"
+			aRe, err := r.mixedRe(s, ms.noCase)
+			if err != nil {
+				t.addError(newRouteError(20043, err.Error(), Route, FileName, LineNo))
+				aRe = neverMatch
+			}
+			tmpRe = append(tmpRe, Re{Pos: i, Re: aRe.String(), cRe: aRe, Names: segNames(s)})
+			reNames = append(reNames, segNames(s)...)
+		default:
+			ss = ss ^ ms.Hash[i]
+			hasWord = true
+			if i < len(segs)-1 || s.Text != "" { // Not for the trailing '/'
+				pp += "T"
 			}
-		} else {
-			ss = ss ^ r.Hash[i]
-			//if dbHash2 {
-			//	fmt.Printf(" ss=%d after adding %d\n", ss, r.Hash[i])
-			//}
-			pp += "T"
 		}
 	}
+	if !hasWord { // All params, /:a/:b, the method is only in the hash of a word
+		ss = ss ^ m
+	}
 	ss = ((ss & bitMask) ^ ((ss >> nBits) & bitMask) ^ ((ss >> (nBits * 2)) & bitMask))
 	//if dbHash2 || dbMatch2 {
 	//	fmt.Printf("After, ss=%-5d m=%4d/%s Url=%s, %s %d, %s\n", ss, m, Method, Route, FileName, LineNo, debug.LF())
@@ -1398,40 +1708,40 @@ func (r *MuxRouter) addHash2Map(Method string, Route string, cleanRoute string,
 		haveRealRe = true
 		//		fmt.Printf("****** Have a AddToM/MatchIt re ******* = len(AddToM)=%d\n", len(AddToM))
 	}
-	if r.Hash2Test[ss] == 0 {
+	if t.Hash2Test[ss] == 0 {
 		//if dbHash2 {
 		// fmt.Printf("At (no collision) %s\n", debug.LF())
-		// fmt.Printf("Adding to empty locaiton in table, r.Hash2Test[ss]==0\n")
+		// fmt.Printf("Adding to empty locaiton in table, t.Hash2Test[ss]==0\n")
 		//}
-		r.Hash2Test[ss] = r.nLookupResults
+		t.Hash2Test[ss] = t.nLookupResults
 		if haveRealRe {
 			///*db*/ fmt.Printf("At %s -- creating ReSet\n", debug.LF())
-			r.LookupResults = append(r.LookupResults, Collision2{cType: SingleUrl, Url: Route, NSL: ns, CleanUrl: cleanRoute,
+			t.LookupResults = append(t.LookupResults, Collision2{cType: SingleUrl, Url: Route, NSL: ns, CleanUrl: cleanRoute,
 				Hdlr: hdlr, Fx: fx, TPat: pp, FileName: FileName, route_i: NFxNo, LineNo: LineNo, ArgNames: names, MatchIt: AddToM,
 				HasRe: []ReList{ReList{Hdlr: hdlr, Fx: fx, ArgNames: reNames, ReSet: tmpRe, MatchIt: AddToM, route_i: NFxNo}}})
 		} else {
 			///*db*/ fmt.Printf("At %s\n", debug.LF())
-			r.LookupResults = append(r.LookupResults, Collision2{cType: SingleUrl, Url: Route, NSL: ns, CleanUrl: cleanRoute,
+			t.LookupResults = append(t.LookupResults, Collision2{cType: SingleUrl, Url: Route, NSL: ns, CleanUrl: cleanRoute,
 				Hdlr: hdlr, Fx: fx, TPat: pp, FileName: FileName, LineNo: LineNo, ArgNames: names, MatchIt: AddToM, route_i: NFxNo})
 		}
 		// fmt.Printf("At %s\n", debug.LF())
-		r.nLookupResults++
+		t.nLookupResults++
 		// Hash Collision ------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------
 	} else { // Have a collision on our hands.
 		//if dbHash2 {
 		// fmt.Printf("At (collision) %s\n", debug.LF())
 		// fmt.Printf("At %s -- Collision for %s\n", debug.LF(), Route)
 		//}
-		c := r.Hash2Test[ss]
-		if optionEarlyExit && r.LookupResults[c].cType == IsWord { // No biggie - just a IsWord marker.
+		c := t.Hash2Test[ss]
+		if optionEarlyExit && t.LookupResults[c].cType == IsWord { // No biggie - just a IsWord marker.
 			if haveRealRe {
 				///*db*/ fmt.Printf("At %s\n", debug.LF())
-				r.LookupResults[c] = Collision2{cType: (SingleUrl | IsWord), Url: Route, NSL: ns, CleanUrl: cleanRoute, Hdlr: hdlr,
+				t.LookupResults[c] = Collision2{cType: (SingleUrl | IsWord), Url: Route, NSL: ns, CleanUrl: cleanRoute, Hdlr: hdlr,
 					Fx: fx, TPat: pp, FileName: FileName, route_i: NFxNo, LineNo: LineNo, ArgNames: names, MatchIt: AddToM,
 					HasRe: []ReList{ReList{Hdlr: hdlr, Fx: fx, ArgNames: reNames, ReSet: tmpRe, MatchIt: AddToM, route_i: NFxNo}}}
 			} else {
 				///*db*/ fmt.Printf("At %s\n", debug.LF())
-				r.LookupResults[c] = Collision2{cType: (SingleUrl | IsWord), Url: Route, NSL: ns, CleanUrl: cleanRoute,
+				t.LookupResults[c] = Collision2{cType: (SingleUrl | IsWord), Url: Route, NSL: ns, CleanUrl: cleanRoute,
 					Hdlr: hdlr, Fx: fx, TPat: pp, FileName: FileName, route_i: NFxNo, LineNo: LineNo, MatchIt: AddToM,
 					ArgNames: names}
 			}
@@ -1440,7 +1750,7 @@ func (r *MuxRouter) addHash2Map(Method string, Route string, cleanRoute string,
 			//if dbHash2 {
 			//	fmt.Printf("+==========================================+\n| Just a collision                         |\n+==========================================+\n")
 			//}
-			old := r.LookupResults[c]
+			old := t.LookupResults[c]
 			if old.HasRe != nil && haveRealRe { // need to check to see if is alreay a RE in old.  If so just append
 				///*db*/ fmt.Printf("At %s\n", debug.LF())
 				//if dbHash2 {
@@ -1467,7 +1777,7 @@ func (r *MuxRouter) addHash2Map(Method string, Route string, cleanRoute string,
 					//	fmt.Printf("After:cType=%04x,%s\n", old.cType, dumpCType(old.cType))
 					//}
 					// fmt.Printf("At %s\n", debug.LF())
-					old.Multi[old.CleanUrl] = r.LookupResults[c]
+					old.Multi[old.CleanUrl] = t.LookupResults[c]
 				}
 
 				// One of the cleanRoute items is the colliding one - we will need to set the RE on that one.
@@ -1501,7 +1811,7 @@ func (r *MuxRouter) addHash2Map(Method string, Route string, cleanRoute string,
 						Fx: fx, TPat: pp, FileName: FileName, LineNo: LineNo, MatchIt: AddToM, ArgNames: names, route_i: NFxNo}
 				}
 			}
-			r.LookupResults[c] = old
+			t.LookupResults[c] = old
 		}
 	}
 	//if dbLookupUrlMap {
@@ -1522,13 +1832,8 @@ type UrlPat struct {
 	PatOcc map[string]int
 }
 
-var nMatch []UrlPat // Index by Length ( NSl )
 // var starPat []string // Longer than max NSl => only match to * items
 
-func init() {
-	nMatch = make([]UrlPat, MaxSlashInUrl, MaxSlashInUrl)
-}
-
 // -------------------------------------------------------------------------------------------------
 /*
 	Degrees of Freedom, 			Lo .. Hi		sortDf(Pat[i])
@@ -1620,12 +1925,16 @@ BenchmarkOfSplitOnSlash3_short	100000000	        21.1 ns/op	       0 B/op
 */
 
 // xyzzy-hash
-func (r *MuxRouter) SplitOnSlash3(m int, Url string, isUrl bool) {
+func (r *MuxRouter) SplitOnSlash3(ms *MatchState, m int, Url string, isUrl bool) {
 	fixed := false
 	var ln, NSl, h, wLen, i int
 	var p, eem bool
 	eem = true
 	pp := true
+	if ms.rt == nil {
+		ms.rt = r.RouteTable()
+	}
+	rt := ms.rt // nil before the first compile, then there are no words so early exit
 	// fmt.Printf("\nAt top: ->%s<- %s\n", Url, debug.LF())
 	if len(Url) == 0 {
 		Url = "/"
@@ -1634,15 +1943,16 @@ func (r *MuxRouter) SplitOnSlash3(m int, Url string, isUrl bool) {
 	}
 s0:
 	// fmt.Printf("At s0: ->%s<- %s\n", Url, debug.LF())
-	r.CurUrl = Url
+	ms.CurUrl = Url
+	ms.early = false
 	ln = len(Url)
 	NSl = 0
 	h = m
 	wLen = 0
 	i = 0
-	r.Hash[NSl] = 0
-	r.Slash[NSl] = 0
-	r.Slash[1] = ln
+	ms.Hash[NSl] = 0
+	ms.Slash[NSl] = 0
+	ms.Slash[1] = ln
 	NSl++
 	p = true
 
@@ -1659,7 +1969,7 @@ s1:
 	if p && (Url[i] == '.' || Url[i] == '/') {
 		if pp {
 			pp = false
-			Url, fixed = r.FixBadUrl(r.CurUrl)
+			Url, fixed = r.FixBadUrl(ms.CurUrl)
 			if fixed {
 				goto s0
 			}
@@ -1670,19 +1980,24 @@ s1:
 		goto s2a
 	}
 	if Url[i] == '/' {
+		if i == ln-1 && !ms.slash && (rt == nil || !rt.strictSlash) { // A trailing '/' is not a word, /api/12/ is /api/12
+			ln = i
+			goto s10
+		}
 		h += wLen
 		h += (h << 3)
 		h = h ^ (h >> 11)
 		h += (h << 15)
-		r.Hash[NSl-1] = h
-		r.Slash[NSl] = i
+		ms.Hash[NSl-1] = h
+		ms.Slash[NSl] = i
 		NSl++
-		r.Slash[NSl] = ln
+		ms.Slash[NSl] = ln
 		if optionEarlyExit {
 			if eem && isUrl {
 				eem = false
-				if r.Hash2Test[(h^m)&bitMask] == 0 {
-					r.NSl = 1
+				if rt == nil || (rt.Hash2Test[(h^m)&bitMask] == 0 && !rt.isParamFirst(m, Url[i:], ms.slash)) {
+					ms.NSl = 1
+					ms.early = true // No route for this method starts with the word, so nothing matches
 					goto s11
 				}
 			}
@@ -1696,7 +2011,11 @@ s1:
 		p = true
 		goto s1
 	}
-	h += int(Url[i])
+	if ms.noCase && 'A' <= Url[i] && Url[i] <= 'Z' {
+		h += int(Url[i] + ('a' - 'A'))
+	} else {
+		h += int(Url[i])
+	}
 	h += (h << 10)
 	h = h ^ (h >> 6)
 	wLen++
@@ -1714,7 +2033,7 @@ s2a:
 	if p && (Url[i] == '.' || Url[i] == '/') {
 		if pp {
 			pp = false
-			Url, fixed = r.FixBadUrl(r.CurUrl)
+			Url, fixed = r.FixBadUrl(ms.CurUrl)
 			if fixed {
 				goto s0
 			}
@@ -1722,19 +2041,24 @@ s2a:
 	}
 	// fmt.Printf("s2a: i=%d url ->%s<- wLen=%d\n", i, Url[i:], wLen)
 	if Url[i] == '/' {
+		if i == ln-1 && !ms.slash && (rt == nil || !rt.strictSlash) { // A trailing '/' is not a word, /api/12/ is /api/12
+			ln = i
+			goto s10
+		}
 		h += wLen
 		h += (h << 3)
 		h = h ^ (h >> 11)
 		h += (h << 15)
-		r.Hash[NSl-1] = h
-		r.Slash[NSl] = i
+		ms.Hash[NSl-1] = h
+		ms.Slash[NSl] = i
 		NSl++
-		r.Slash[NSl] = ln
+		ms.Slash[NSl] = ln
 		if optionEarlyExit {
 			if eem && isUrl {
 				eem = false
-				if r.Hash2Test[(h^m)&bitMask] == 0 {
-					r.NSl = 1
+				if rt == nil || (rt.Hash2Test[(h^m)&bitMask] == 0 && !rt.isParamFirst(m, Url[i:], ms.slash)) {
+					ms.NSl = 1
+					ms.early = true // No route for this method starts with the word, so nothing matches
 					goto s11
 				}
 			}
@@ -1755,24 +2079,24 @@ s2a:
 s10:
 	// fmt.Printf("At s10: i=%d %s\n", i, debug.LF())
 	// fmt.Printf("s10: wLen=%d\n", wLen)
-	if wLen > 0 {
+	if wLen > 0 || i > 1 { // i > 1 && wLen == 0 is a trailing '/' with StrictSlash, the last word is empty
 		h += (h << 3)
 		h = h ^ (h >> 11)
 		h += (h << 15)
 		h += wLen
-		r.Hash[NSl-1] = h
-		r.Slash[NSl] = ln
-		r.NSl = NSl
+		ms.Hash[NSl-1] = h
+		ms.Slash[NSl] = ln
+		ms.NSl = NSl
 	} else if i == 1 {
 		//if oneSlash {
 		//	fmt.Printf("Special Case, Url=->%s<- %s\n", Url, debug.LF())
 		//}
-		r.Hash[0] = h
-		r.Slash[1] = ln
-		r.NSl = 1
+		ms.Hash[0] = h
+		ms.Slash[1] = ln
+		ms.NSl = 1
 	}
 	//if false {
-	//	fmt.Printf("Hash=%s Slash=%s NSl=%d\n", debug.SVar(r.Hash[0:r.NSl]), debug.SVar(r.Slash[0:r.NSl+1]), r.NSl)
+	//	fmt.Printf("Hash=%s Slash=%s NSl=%d\n", debug.SVar(ms.Hash[0:ms.NSl]), debug.SVar(ms.Slash[0:ms.NSl+1]), ms.NSl)
 	//}
 s11:
 	// fmt.Printf("At s11: i=%d pp=%v %s\n", i, pp, debug.LF())
@@ -1783,73 +2107,67 @@ s11:
 func (r *MuxRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	m := 0
 
-	//r.www0.StartTime = time.Now() // 25ns
-	//r.www0.Status = http.StatusOK // 1ns
-	//r.www0.ResponseBytes = 0      // 1ns
-	//r.www0.w = w                  // 1ns
+	// All per-request data is in ms, the router itself is read-only at this point.  // PJS
+	ms := r.getMatchState()
+	defer r.putMatchState(ms)
 
-	//var r_www *MyResponseWriter
-	// r_www = &MyResponseWriter{}  // Horrid!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!! -- Malloc call 400ns
-	var rr_www MyResponseWriter  // PJS - Sun Nov 15 13:20:01 MST 2015
-	r_www := &rr_www             // just put on stack
+	r_www := &ms.www             // In the pooled MatchState, so no malloc call (was 400ns)
 	r_www.StartTime = time.Now() // 25ns
 	r_www.Status = http.StatusOK // 1ns
 	r_www.ResponseBytes = 0      // 1ns
 	r_www.w = w                  // 1ns
-
-	// Sun Feb 22 12:51:24 MST 2015 -- New PJS
-	// var allParam [MaxParams]Param // The parameters for the current operation
-	// r.AllParam.Data = allParam[:]
-	// r.AllParam.search = make(map[string]int) // Horrid!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!
-	// r.AllParam.parent = r
-	// Sun Feb 22 12:51:24 MST 2015 -- End New PJS
-	// PJS Sun Nov 15 13:17:37 MST 2015
-	InitParams(&r.AllParam)
-	// end PJS Sun Nov 15 13:17:37 MST 2015
+	r_www.discardBody = false    // 1ns
 
 	if r.PanicHandler != nil { // 2ns
 		defer r.recv(w, req)
 	}
-	if !r.HasBeenCompiled { // 2ns
-		r.CompileRoutes()
-	}
+	ms.rt = r.servingTable() // 2ns, this request will finish on this table, even if a new one is swapped in.
 
-	// r.AllParam.NParam = 0
 	if r.widgetBefore != nil {
 		for _, x := range r.widgetBefore {
-			m = x.fx(r_www, req, &r.AllParam)
+			m = x.fx(r_www, req, &ms.AllParam)
 		}
 	}
 
-	path := req.URL.Path
+	path := r.routePath(req)
 	Method := req.Method
 	m = (int(Method[0]) + (int(Method[1]) << 1))
 
 	if r.widgetHashNewM != nil {
 		for _, x := range r.widgetHashNewM {
-			m = x.fx(r_www, req, &r.AllParam)
+			m = x.fx(r_www, req, &ms.AllParam)
 		}
 	}
 
-	r.SplitOnSlash3(m, path, true)
-	found, ln, item := r.LookupUrlViaHash2(w, req, &m)
+	found, ln, item := r.lookupPath(ms, w, req, m, path)
 	// if dbLookup4 {
 	// fmt.Printf("found=%v, %s\n", found, debug.LF())
 	// }
-	if found {
+	if !found && r.HandleHEAD && Method == "HEAD" { // Try the GET route
+		found, ln, item = r.lookupPath(ms, w, req, (int('G') + (int('E') << 1)), path)
+		r_www.discardBody = found
+	}
+	if found && r.RedirectFixed && ms.CurUrl != path {
+		r.redirect(w, req, ms.CurUrl)
+	} else if to, changed := r.caseRedirect(ms, found, item.route_i); changed {
+		r.redirect(w, req, to)
+	} else if found {
 		// fmt.Printf("Was Found!  Getting args now\n")
-		r.GetArgs3(path, item.ArgPattern, item.ArgNames, ln)
-		// fmt.Printf("Was Found!  Calling Fx, params=%s\n", r.AllParam.DumpParam())
-		r.AllParam.route_i = item.route_i
-		// fmt.Printf("Found, parsing paras for route_i=%d\n", r.AllParam.route_i)
-		item.Fx(r_www, req, r.AllParam)
+		r.GetArgs3(ms, ms.CurUrl, item.ReSet, item.ArgNames, ln)
+		// fmt.Printf("Was Found!  Calling Fx, params=%s\n", ms.AllParam.DumpParam())
+		ms.AllParam.route_i = item.route_i
+		// fmt.Printf("Found, parsing paras for route_i=%d\n", ms.AllParam.route_i)
+		item.Fx(r_www, req, ms.AllParam)
+		if r_www.discardBody {
+			r_www.finishHead()
+		}
 	} else {
-		r.NotFound(w, req)
+		r.noMatch(ms, w, req, path)
 	}
 
 	if r.widgetAfter != nil {
 		for _, x := range r.widgetAfter {
-			m = x.fx(r_www, req, &r.AllParam)
+			m = x.fx(r_www, req, &ms.AllParam)
 		}
 	}
 
@@ -1859,38 +2177,35 @@ func (r *MuxRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 func (r *MuxRouter) MatchAndServeHTTP(www http.ResponseWriter, req *http.Request) (Found bool) {
 	m := 0
 
+	// xyzzyGoFtl01 - Remove in favor of Ps in buffer
+	ms := r.getMatchState()
+	defer r.putMatchState(ms)
+
 	// xyzzyGoFtl01 - Make this the buffer we use in Go-FTL
-	var rr_www MyResponseWriter  // PJS - Sun Nov 15 13:20:01 MST 2015
-	r_www := &rr_www             // just put on stack
+	r_www := &ms.www             // In the pooled MatchState
 	r_www.StartTime = time.Now() // 25ns
 	r_www.Status = http.StatusOK // 1ns
 	r_www.ResponseBytes = 0      // 1ns
 	r_www.w = www                // 1ns
 
-	// xyzzyGoFtl01 - Remove in favor of Ps in buffer
-	InitParams(&r.AllParam)
+	ms.rt = r.servingTable() // 2ns
 
-	if !r.HasBeenCompiled { // 2ns
-		r.CompileRoutes()
-	}
-
-	path := req.URL.Path
+	path := r.routePath(req)
 	Method := req.Method
 	m = (int(Method[0]) + (int(Method[1]) << 1))
 
-	r.SplitOnSlash3(m, path, true)
-	found, ln, item := r.LookupUrlViaHash2(www, req, &m)
+	found, ln, item := r.lookupPath(ms, www, req, m, path)
 	Found = found
 	// if dbLookup4 {
 	// fmt.Printf("found=%v, %s\n", found, debug.LF())
 	// }
 	if found {
 		// fmt.Printf("Was Found!  Getting args now\n")
-		r.GetArgs3(path, item.ArgPattern, item.ArgNames, ln)
-		// fmt.Printf("Was Found!  Calling Fx, params=%s\n", r.AllParam.DumpParam())
-		r.AllParam.route_i = item.route_i // xyzzyGoFtl01 - Remove in favor of Ps in buffer
-		// fmt.Printf("Found, parsing paras for route_i=%d\n", r.AllParam.route_i)
-		item.Fx(r_www, req, r.AllParam) // xyzzyGoFtl01 - Convert to buffer for TabServer2
+		r.GetArgs3(ms, ms.CurUrl, item.ReSet, item.ArgNames, ln)
+		// fmt.Printf("Was Found!  Calling Fx, params=%s\n", ms.AllParam.DumpParam())
+		ms.AllParam.route_i = item.route_i // xyzzyGoFtl01 - Remove in favor of Ps in buffer
+		// fmt.Printf("Found, parsing paras for route_i=%d\n", ms.AllParam.route_i)
+		item.Fx(r_www, req, ms.AllParam) // xyzzyGoFtl01 - Convert to buffer for TabServer2
 	}
 
 	return
@@ -1919,26 +2234,231 @@ func (r *MuxRouter) ServeFiles(path string, root http.FileSystem) {
 		req.URL.Path = ps.ByName("filepath")
 		fileServer.ServeHTTP(w, req)
 	}
-	k := len(r.routes)
-	r.NewRoute().HandleFunc(path, fx)
-	r.addRoute("GET", path, 0, fx, k, FileName, LineNo)
+	x := r.NewRoute().HandleFunc(path, fx).Methods("GET")
+	x.FileName, x.LineNo = FileName, LineNo
+}
+
+// The path from the request that is used for routing.
+func (r *MuxRouter) routePath(req *http.Request) string {
+	if r.UseRawPath {
+		return req.URL.EscapedPath()
+	}
+	return req.URL.Path
+}
+
+// Split the path and look it up.  If there is no match and some routes are case-insensitive
+// then try again without case, only accepting a case-insensitive route.
+func (r *MuxRouter) lookupPath(ms *MatchState, w http.ResponseWriter, req *http.Request, m int, path string) (found bool, ln int, item Collision2) {
+	ms.noCase = r.CaseInsensitive
+	found, ln, item = r.lookupSplit(ms, w, req, m, path)
+	if !found && !ms.noCase && ms.rt.noCase {
+		ms.noCase = true
+		found, ln, item = r.lookupSplit(ms, w, req, m, path)
+		found = found && ms.rt.routes[item.route_i].DNoCase
+	} else if found && ms.rt.routes[item.route_i].DNoCase {
+		ms.noCase = true // A case-insensitive route matched a URL that was already lower case
+	}
+	return
+}
+
+// Split the path and look it up.  Without StrictSlash a trailing '/' is first tried as an
+// empty last word, so /static/ is /static/*rest, and then it is dropped, /api/12/ is /api/:id.
+func (r *MuxRouter) lookupSplit(ms *MatchState, w http.ResponseWriter, req *http.Request, m int, path string) (found bool, ln int, item Collision2) {
+	ms.slash = !ms.rt.strictSlash && len(path) > 1 && path[len(path)-1] == '/'
+	r.SplitOnSlash3(ms, m, path, true)
+	found, ln, item = r.LookupUrlViaHash2(ms, w, req, &m)
+	if !found && ms.slash {
+		ms.slash = false
+		r.SplitOnSlash3(ms, m, path, true)
+		found, ln, item = r.LookupUrlViaHash2(ms, w, req, &m)
+	}
+	ms.slash = false
+	return
+}
+
+// For RedirectCase, the path to redirect to when a case-insensitive route matched a URL
+// that does not have the case used in the route.
+func (r *MuxRouter) caseRedirect(ms *MatchState, found bool, route_i int) (string, bool) {
+	if !found || !ms.noCase || !r.RedirectCase {
+		return "", false
+	}
+	return r.routeCasePath(ms, route_i)
+}
+
+// The URL with the literals in the case used in the route and the params from the URL.
+// It is built from the parsed route with its PathPrefix, so `/ns/\:system` is /ns/:system.
+// changed is false if the URL already has the case of the route.
+func (r *MuxRouter) routeCasePath(ms *MatchState, route_i int) (rv string, changed bool) {
+	word := func(i int, rest bool) string {
+		s := ""
+		if j := ms.Slash[i] + 1; j < len(ms.CurUrl) && rest {
+			s = ms.CurUrl[j:]
+		} else if j < ms.Slash[i+1] {
+			s = ms.CurUrl[j:ms.Slash[i+1]]
+		}
+		if r.UseRawPath {
+			return unescapeParam(s)
+		}
+		return s
+	}
+	for _, p := range routePaths(ms.rt.routes[route_i]) {
+		segs, err := ParseRoute(p)
+		if n := len(segs) - 1; err == nil && n > 0 && !ms.rt.strictSlash && segs[n].Kind == SegLiteral && segs[n].Text == "" {
+			segs = segs[:n] // Without StrictSlash the URL keeps its own trailing '/'
+		}
+		if err != nil || len(segs) > ms.NSl || (len(segs) < ms.NSl && segs[len(segs)-1].Kind != SegCatchAll) {
+			continue // Not the path of the route that matched
+		}
+		path := ""
+		for i, s := range segs {
+			switch s.Kind {
+			case SegLiteral:
+				path += "/" + s.Text
+				changed = changed || word(i, false) != s.Text
+			case SegCatchAll:
+				path += "/" + word(i, true)
+			default:
+				path += "/" + word(i, false)
+			}
+		}
+		if !ms.rt.strictSlash && len(path) > 1 && path[len(path)-1] != '/' && ms.CurUrl[len(ms.CurUrl)-1] == '/' {
+			path += "/"
+		}
+		return (&url.URL{Path: path}).EscapedPath(), changed
+	}
+	return "", false
+}
+
+// No route matched.  Redirect if only the trailing slash is different, answer OPTIONS,
+// or 405 if the path has routes for other methods, otherwise call NotFound.
+func (r *MuxRouter) noMatch(ms *MatchState, w http.ResponseWriter, req *http.Request, path string) {
+	if r.UseRedirect && len(path) > 1 {
+		other := path + "/"
+		if path[len(path)-1] == '/' {
+			other = path[:len(path)-1]
+		}
+		if found, _, _ := r.lookupPath(ms, nil, req, MethodToCode(req.Method, 0), other); found {
+			r.redirect(w, req, other)
+			return
+		}
+	}
+	isOptions := r.HandleOPTIONS && req.Method == "OPTIONS"
+	if isOptions || r.MethodNotAllowed != nil {
+		if allow := r.allowedMethods(ms, req, path); allow != "" {
+			if isOptions {
+				w.Header().Set("Allow", allow+", OPTIONS")
+				if r.OptionsHandler != nil {
+					r.OptionsHandler(w, req)
+				} else {
+					w.WriteHeader(http.StatusOK)
+				}
+				return
+			}
+			w.Header().Set("Allow", allow)
+			r.MethodNotAllowed(w, req)
+			return
+		}
+	}
+	r.NotFound(w, req)
+}
+
+// Redirect to path, keeping the query.  GET and HEAD get a 301, other methods a 308
+// so that the client sends the same method and body to the new URL.
+func (r *MuxRouter) redirect(w http.ResponseWriter, req *http.Request, path string) {
+	code := http.StatusMovedPermanently
+	if req.Method != "GET" && req.Method != "HEAD" {
+		code = http.StatusPermanentRedirect
+	}
+	if req.URL.RawQuery != "" {
+		path += "?" + req.URL.RawQuery
+	}
+	http.Redirect(w, req, path, code)
+}
+
+// Find the other methods that have a route for path.  Returns them as the value for an
+// Allow header, "" if there are none.  With HandleHEAD a GET route also allows HEAD.
+func (r *MuxRouter) allowedMethods(ms *MatchState, req *http.Request, path string) (allow string) {
+	var list []string
+	get, head := false, false
+	for _, method := range ms.rt.methods {
+		if method == req.Method {
+			continue
+		}
+		if found, _, _ := r.lookupPath(ms, nil, req, MethodToCode(method, 0), path); found {
+			list = append(list, method)
+			get = get || method == "GET"
+			head = head || method == "HEAD"
+		}
+	}
+	if r.HandleHEAD && get && !head && req.Method != "HEAD" {
+		list = append(list, "HEAD")
+		sort.Strings(list)
+	}
+	return strings.Join(list, ", ")
+}
+
+// NotAllowed replies to the request with an HTTP 405 error.  It can be used for
+// MethodNotAllowed in MuxRouter.
+func NotAllowed(w http.ResponseWriter, req *http.Request) {
+	http.Error(w, "405 method not allowed", http.StatusMethodNotAllowed)
 }
 
 // Lookup allows the manual lookup of a method + path combo.
 // This is e.g. useful to build a framework around this router.
-func (r *MuxRouter) Lookup(method, path string) (HandleFunc, Params, bool) {
-	//if root := r.trees[method]; root != nil {
-	//	return root.getValue(path)
-	//}
-	r.AllParam.NParam = 0
-	for _, v := range r.routeData {
-		_ = v
-		// xyzzy xyzzy - fix this
-		//if v.Route == path {
-		//	return FxTab.Fx[v.NFxNo], r.AllParam, true
-		//}
+// With UseRawPath the path is the escaped path.
+// The same match as ServeHTTP is done but the handler is not called.  Routes that
+// match on host, port, headers, TLS or protocal need a request, pass it as req.
+// Without one a request with just the method and path is used.  The returned Params
+// are a copy and can be kept.
+func (r *MuxRouter) Lookup(method, path string, req ...*http.Request) (HandleFunc, Params, *ARoute, bool) {
+	var rq *http.Request
+	if len(req) > 0 && req[0] != nil {
+		rq = req[0]
+	} else {
+		rq = &http.Request{Method: method, URL: &url.URL{Path: path}, Header: make(http.Header)}
+	}
+	var rm RouteMatch
+	if !r.matchRoute(method, path, rq, &rm) {
+		return nil, Params{}, nil, false
+	}
+	return rm.Handler, *rm.Params, rm.Route, true
+}
+
+// Match finds the route for the request without calling the handler.  This is the
+// same match as ServeHTTP, so it can be used to check a request before it is served.
+func (r *MuxRouter) Match(req *http.Request) (*RouteMatch, bool) {
+	rm := &RouteMatch{}
+	if !r.matchRoute(req.Method, r.routePath(req), req, rm) {
+		return nil, false
+	}
+	return rm, true
+}
+
+// Do the match for Lookup and Match, filling in rm.
+func (r *MuxRouter) matchRoute(method, path string, req *http.Request, rm *RouteMatch) bool {
+	if len(method) < 2 {
+		return false
+	}
+
+	ms := r.getMatchState()
+	defer r.putMatchState(ms)
+
+	ms.rt = r.servingTable()
+
+	found, ln, item := r.lookupPath(ms, nil, req, MethodToCode(method, 0), path)
+	if !found {
+		return false
 	}
-	return nil, r.AllParam, false
+	r.GetArgs3(ms, ms.CurUrl, item.ReSet, item.ArgNames, ln)
+	ms.AllParam.route_i = item.route_i
+
+	rm.Route = ms.rt.from[item.route_i]
+	rm.Handler = item.Fx
+	p := ms.AllParam.Copy()
+	rm.Params = &p
+	rm.UsePat = ms.UsePat
+	rm.CType = item.cType
+	return true
 }
 
 func (r *MuxRouter) recv(w http.ResponseWriter, req *http.Request) {
@@ -1947,8 +2467,9 @@ func (r *MuxRouter) recv(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request, m *int) (found bool, ln int, rv Collision2) {
-	Url := r.CurUrl
+func (r *MuxRouter) LookupUrlViaHash2(ms *MatchState, w http.ResponseWriter, req *http.Request, m *int) (found bool, ln int, rv Collision2) {
+	t := ms.rt
+	Url := ms.CurUrl
 	found = false
 	ln = len(Url)
 	var ss int
@@ -1958,26 +2479,30 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 	//if dbLookupUrlMap {
 	//	fmt.Printf("\n\nLookupUrlViaHash2: Top of Lookup test %s\n", debug.LF())
 	//}
-	if r.NSl > minInt(MaxSlashInUrl-1, r.MaxSlash+1) {
-		r.NSl = minInt(MaxSlashInUrl-1, r.MaxSlash+1)
+	if ms.early {
+		return
+	}
+	if ms.NSl > minInt(MaxSlashInUrl-1, t.MaxSlash+1) {
+		ms.NSl = minInt(MaxSlashInUrl-1, t.MaxSlash+1)
 	}
 	//if dbLookupUrlMap2 {
-	//	fmt.Printf("LookupUrlViaHash2: %s, r.NSl=%d, len(nMatch[%d].PatList)=%d\n", debug.LF(), r.NSl, r.NSl, len(nMatch[r.NSl].PatList))
-	//	fmt.Printf("LookupUrlViaHash2: nMatch[%d]=%s\n", r.NSl, debug.SVarI(nMatch[r.NSl]))
+	//	fmt.Printf("LookupUrlViaHash2: %s, ms.NSl=%d, len(nMatch[%d].PatList)=%d\n", debug.LF(), ms.NSl, ms.NSl, len(nMatch[ms.NSl].PatList))
+	//	fmt.Printf("LookupUrlViaHash2: nMatch[%d]=%s\n", ms.NSl, debug.SVarI(nMatch[ms.NSl]))
 	//	fmt.Printf("nMatch=%s\n", debug.SVarI(nMatch))
 	//}
-	k2 := len(nMatch[r.NSl].PatList)
+	k2 := len(t.nMatch[ms.NSl].PatList)
 	//if dbHash2 {
-	// fmt.Printf("k2 = %d, r.NSl=%d, %s\n", k2, r.NSl, debug.LF())
+	// fmt.Printf("k2 = %d, ms.NSl=%d, %s\n", k2, ms.NSl, debug.LF())
 	//}
 	for jj := 0; jj < k2; jj++ {
 		ss = 0
-		xPat := nMatch[r.NSl].PatList[jj].Pat
+		hasWord := false
+		xPat := t.nMatch[ms.NSl].PatList[jj].Pat
 		//if dbHash2 {
 		// fmt.Printf("Top of Pat Match Loop, jj=%d pat=%s, %s\n", jj, xPat, debug.LF())
 		//}
-		r.UsePat = xPat
-		for i := 0; i < r.NSl; i++ {
+		ms.UsePat = xPat
+		for i := 0; i < ms.NSl; i++ {
 			if xPat[i] == ':' {
 				ss += 153
 				//if dbHash2 {
@@ -1995,30 +2520,34 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 				// fmt.Printf(" ss=%d after { 211, %s\n", ss, debug.LF())
 				//}
 			} else {
-				ss = ss ^ r.Hash[i]
+				ss = ss ^ ms.Hash[i]
+				hasWord = true
 				//if dbHash2 {
-				// fmt.Printf(" ss=%d after adding %d, %s\n", ss, r.Hash[i], debug.LF())
+				// fmt.Printf(" ss=%d after adding %d, %s\n", ss, ms.Hash[i], debug.LF())
 				//}
 			}
 		}
+		if !hasWord {
+			ss = ss ^ *m
+		}
 		ss = ((ss & bitMask) ^ ((ss >> nBits) & bitMask) ^ ((ss >> (nBits * 2)) & bitMask))
 		//if dbLookup4 {
 		// fmt.Printf("ss=%s, %s\n", debug.SVar(ss), debug.LF())
 		//}
 		//if dbLookup4 {
-		// for x := range r.Hash2Test {
-		// 	if r.Hash2Test[x] > 0 {
-		// 		fmt.Printf("  **** r.Hash2Test[%d]=%d\n", x, r.Hash2Test[x])
+		// for x := range t.Hash2Test {
+		// 	if t.Hash2Test[x] > 0 {
+		// 		fmt.Printf("  **** t.Hash2Test[%d]=%d\n", x, t.Hash2Test[x])
 		// 	}
 		// }
 		//}
-		if r.Hash2Test[ss] > 0 {
+		if t.Hash2Test[ss] > 0 {
 			//if dbHash2 {
-			// fmt.Printf("Found [%d] at ss=%d, %s\n", r.Hash2Test[ss], ss, debug.LF())
+			// fmt.Printf("Found [%d] at ss=%d, %s\n", t.Hash2Test[ss], ss, debug.LF())
 			//}
 			// 1. match all the constants and disambiguate.  If Collision2 - has REFlag set to true ( > 0 Re Sets ) then iterate over them to see if we get a match.
 			// xyzzy-widget
-			c := r.LookupResults[r.Hash2Test[ss]]
+			c := t.LookupResults[t.Hash2Test[ss]]
 			if (c.cType & SingleUrl) != 0 {
 				//if dbHash2 {
 				// fmt.Printf("Found a SingleUrl to be true - just return success?? what about RE, %s\n", debug.LF())
@@ -2037,7 +2566,7 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 						reMatch = true
 						for m, x := range ww.ReSet {
 							_ = m
-							if !x.cRe.MatchString(Url[r.Slash[x.Pos]+1 : r.Slash[x.Pos+1]]) {
+							if !x.cRe.MatchString(Url[ms.Slash[x.Pos]+1 : ms.Slash[x.Pos+1]]) {
 								//if dbHash2 {
 								//	fmt.Printf("Found false match on set k=%d\n", k)
 								//}
@@ -2053,12 +2582,13 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 							// xyzzy-widget -- Final matching on user stuff
 							if ww.MatchIt != nil {
 								///*db*/ fmt.Printf("At %s\n", debug.LF())
-								if r.WidgetMatch(ww.MatchIt, w, req, m, ww.route_i) {
+								if r.WidgetMatch(ms, ww.MatchIt, w, req, m, ww.route_i) {
 									found = true
 									rv.Hdlr = ww.Hdlr
 									rv.Fx = ww.Fx
 									rv.route_i = ww.route_i
 									rv.ArgNames = ww.ArgNames
+									rv.ReSet = ww.ReSet
 									return
 								}
 							} else {
@@ -2068,6 +2598,7 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 								rv.Fx = ww.Fx
 								rv.route_i = ww.route_i
 								rv.ArgNames = ww.ArgNames
+								rv.ReSet = ww.ReSet
 								return
 							}
 						}
@@ -2075,7 +2606,7 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 					}
 				} else {
 					/* Problem at this locaiton xyzzy xyzzy */
-					// r.UrlToCleanRoute3(r.UsePat) // URL to cRoute? -- URL --
+					// r.UrlToCleanRoute3(ms.UsePat) // URL to cRoute? -- URL --
 					// if dbHash2 {
 					// fmt.Printf("NO RE match is found?? TPat=->%s<-, match v.s. URL ->%s<- CleanUrl ->%s<- cRoute ->%s<-\n", c.TPat, c.Url, c.CleanUrl, cRoute)
 					// }
@@ -2083,13 +2614,13 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 					//if dbHash2 {
 					//	fmt.Printf("NO RE match is found?? TPat=->%s<-, match v.s. URL ->%s<- CleanUrl ->%s<-\n", c.TPat, c.Url, c.CleanUrl)
 					//}
-					if r.CmpUrlToCleanRoute(r.UsePat, c.CleanUrl) {
+					if r.CmpUrlToCleanRoute(ms, ms.UsePat, c.CleanUrl) {
 						//if dbHash2 {
 						// fmt.Printf("   Matched on absolute pattern, returning success\n")
 						//}
 						// xyzzy-widget -- Final matching on user stuff
 						if c.MatchIt != nil {
-							if r.WidgetMatch(c.MatchIt, w, req, m, c.route_i) {
+							if r.WidgetMatch(ms, c.MatchIt, w, req, m, c.route_i) {
 								//if dbHash2 {
 								//		fmt.Printf("   Widget Match Found\n")
 								//}
@@ -2113,7 +2644,7 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 				}
 			} else if (c.cType & MultiUrl) != 0 {
 				// xyzzy - this is where to use NSL
-				cRoute = r.UrlToCleanRoute(r.UsePat) // xyzzy- will alloc memory on call- URL to cRoute? -- URL --
+				cRoute = r.UrlToCleanRoute(ms, ms.UsePat) // xyzzy- will alloc memory on call- URL to cRoute? -- URL --
 				if c2, ok := c.Multi[cRoute]; ok {
 					if c2.HasRe != nil { // if we have a RE-List - then Iterate over it for a match.
 						///*db*/ fmt.Printf("At %s\n", debug.LF())
@@ -2123,7 +2654,7 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 							///*db*/ fmt.Printf("At %s\n", debug.LF())
 							for m, x := range ww.ReSet {
 								_ = m
-								if !x.cRe.MatchString(Url[r.Slash[x.Pos]+1 : r.Slash[x.Pos+1]]) {
+								if !x.cRe.MatchString(Url[ms.Slash[x.Pos]+1 : ms.Slash[x.Pos+1]]) {
 									reMatch = false
 									goto next2
 								}
@@ -2132,12 +2663,13 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 								// xyzzy-widget -- Final matching on user stuff
 								if ww.MatchIt != nil {
 									///*db*/ fmt.Printf("At %s\n", debug.LF())
-									if r.WidgetMatch(ww.MatchIt, w, req, m, ww.route_i) {
+									if r.WidgetMatch(ms, ww.MatchIt, w, req, m, ww.route_i) {
 										found = true
 										rv.Hdlr = ww.Hdlr
 										rv.Fx = ww.Fx
 										rv.route_i = ww.route_i
 										rv.ArgNames = ww.ArgNames
+										rv.ReSet = ww.ReSet
 										return
 									}
 								} else {
@@ -2147,6 +2679,7 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 									rv.Fx = ww.Fx
 									rv.route_i = ww.route_i
 									rv.ArgNames = ww.ArgNames
+									rv.ReSet = ww.ReSet
 									return
 								}
 							}
@@ -2155,7 +2688,7 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 					} else {
 						// xyzzy-widget -- Final matching on user stuff
 						if c2.MatchIt != nil {
-							if r.WidgetMatch(c2.MatchIt, w, req, m, c2.route_i) {
+							if r.WidgetMatch(ms, c2.MatchIt, w, req, m, c2.route_i) {
 								found = true
 								rv = c2
 								return
@@ -2176,14 +2709,16 @@ func (r *MuxRouter) LookupUrlViaHash2(w http.ResponseWriter, req *http.Request,
 // xyzzy - not take into account ReList -
 // xyzzy - remove m *int param?? - not used
 // xyzzy - remov eMatchIt[i].Data?? - not used
-func (r *MuxRouter) WidgetMatch(MatchIt []Match, w http.ResponseWriter, req *http.Request, m *int, route_i int) bool {
+func (r *MuxRouter) WidgetMatch(ms *MatchState, MatchIt []Match, w http.ResponseWriter, req *http.Request, m *int, route_i int) bool {
+	nParam := ms.AllParam.NParam // Params added by a match that fails are removed
 	if MatchIt != nil {
 		for i, v := range MatchIt {
 			_ = i
 			// b := v.MatchFunc(req, r, v.Data)
-			b := v.MatchFunc(req, r, route_i)
-			fmt.Printf("MatchFunc [%d] == %v, with route_i = %d, req.RequestURI=%s, %s\n", i, b, route_i, req.RequestURI, debug.LF())
+			b := v.MatchFunc(req, r, ms, route_i)
 			if !b {
+				ms.AllParam.NParam = nParam
+				ms.AllParam.search_ready = false
 				return false
 			}
 		}
@@ -2194,7 +2729,7 @@ func (r *MuxRouter) WidgetMatch(MatchIt []Match, w http.ResponseWriter, req *htt
 // Input:  Pattern like T::T and the current URL with Slash locaiton information.
 // So... /abc/:def/ghi is the Route, /abc/x/ghi is the ULR, Slash is [ 0, 4, 6, 10 ]
 // The output is /abc/:/ghi - Sutiable for lookup in a map of cleanRoute
-func (r *MuxRouter) UrlToCleanRoute(UsePat string) (rv string) {
+func (r *MuxRouter) UrlToCleanRoute(ms *MatchState, UsePat string) (rv string) {
 	for i, v := range UsePat { // Pat is T::T format pattern
 		if v == ':' {
 			rv += "/:"
@@ -2203,15 +2738,17 @@ func (r *MuxRouter) UrlToCleanRoute(UsePat string) (rv string) {
 			break
 		} else if v == '{' {
 			rv += "/{"
+		} else if ms.noCase {
+			rv += "/" + strings.ToLower(ms.CurUrl[ms.Slash[i]+1:ms.Slash[i+1]])
 		} else {
-			rv += "/" + r.CurUrl[r.Slash[i]+1:r.Slash[i+1]]
+			rv += "/" + ms.CurUrl[ms.Slash[i]+1:ms.Slash[i+1]]
 		}
 	}
 	return
 }
 
-// compate r.CurUrl to a Pattern
-func (r *MuxRouter) CmpUrlToCleanRoute(UsePat string, CleanUrl string) (rv bool) {
+// compate ms.CurUrl to a Pattern
+func (r *MuxRouter) CmpUrlToCleanRoute(ms *MatchState, UsePat string, CleanUrl string) (rv bool) {
 	rv = true
 	// for i, v := range UsePat { // Pat is T::T format pattern
 	k := 1 // Index into CleanUrl
@@ -2230,28 +2767,28 @@ func (r *MuxRouter) CmpUrlToCleanRoute(UsePat string, CleanUrl string) (rv bool)
 		} else if v == '{' {
 			k += 2
 		} else {
-			// r.cRoute += "/" + r.CurUrl[r.Slash[i]+1:r.Slash[i+1]]
-			l := r.Slash[i+1] - r.Slash[i] - 1
+			// r.cRoute += "/" + ms.CurUrl[ms.Slash[i]+1:ms.Slash[i+1]]
+			l := ms.Slash[i+1] - ms.Slash[i] - 1
 			//if dbCmp {
 			//	fmt.Printf("T: l=%d\n", l)
-			//	fmt.Printf("T: left=->%s<-\n", r.CurUrl[r.Slash[i]+1:r.Slash[i+1]])
+			//	fmt.Printf("T: left=->%s<-\n", ms.CurUrl[ms.Slash[i]+1:ms.Slash[i+1]])
 			//	fmt.Printf("T: rght=->%s<-\n", CleanUrl[k:k+l])
 			//}
 			//fmt.Printf("\nX: l=%d\n", l)
 			//fmt.Printf("X: i=%d\n", i)
-			//fmt.Printf("X: len(r.Slash)=%d\n", len(r.Slash))
-			//fmt.Printf("X: len(r.CurUrl)=%d\n", len(r.CurUrl))
-			//fmt.Printf("X: r.Slash[i]= %d\n", r.Slash[i])
-			//fmt.Printf("X: r.Slash[i+1]= %d\n", r.Slash[i+1])
+			//fmt.Printf("X: len(ms.Slash)=%d\n", len(ms.Slash))
+			//fmt.Printf("X: len(ms.CurUrl)=%d\n", len(ms.CurUrl))
+			//fmt.Printf("X: ms.Slash[i]= %d\n", ms.Slash[i])
+			//fmt.Printf("X: ms.Slash[i+1]= %d\n", ms.Slash[i+1])
 			//fmt.Printf("X: k=%d, k+l=%d, len(CleanUrl)=%d\n", k, k+l, len(CleanUrl))
 			//fmt.Printf("X: CleanUrl=->%s<-\n", CleanUrl)
-			//fmt.Printf("X: r.CurUrl=->%s<-\n", r.CurUrl)
+			//fmt.Printf("X: ms.CurUrl=->%s<-\n", ms.CurUrl)
 			m := k + l
 			if m > len(CleanUrl) {
 				m = len(CleanUrl)
 			}
 			//fmt.Printf("X: m=%d\n", m)
-			if r.CurUrl[r.Slash[i]+1:r.Slash[i+1]] != CleanUrl[k:m] {
+			if w := ms.CurUrl[ms.Slash[i]+1 : ms.Slash[i+1]]; w != CleanUrl[k:m] && !(ms.noCase && strings.EqualFold(w, CleanUrl[k:m])) {
 				//if dbCmp {
 				//	fmt.Printf("match failed\n")
 				//}
@@ -2261,13 +2798,13 @@ func (r *MuxRouter) CmpUrlToCleanRoute(UsePat string, CleanUrl string) (rv bool)
 			/*
 			   X: l=18
 			   X: i=2
-			   X: len(r.Slash)=21
-			   X: len(r.CurUrl)=29
-			   X: r.Slash[i]= 10
-			   X: r.Slash[i+1]= 29
+			   X: len(ms.Slash)=21
+			   X: len(ms.CurUrl)=29
+			   X: ms.Slash[i]= 10
+			   X: ms.Slash[i+1]= 29
 			   X: k=11, k+l=29, len(CleanUrl)=12
 			   X: CleanUrl=->/api/table/:<-
-			   X: r.CurUrl=->/api/table/user_valid_origins<-
+			   X: ms.CurUrl=->/api/table/user_valid_origins<-
 			*/
 			k += l + 1
 		}
@@ -2289,21 +2826,6 @@ func LastIndexOfChar(s string, ch uint8) int {
 	return -1
 }
 
-/*
-// From Gorilla-Mux
-// uniqueVars returns an error if two slices contain duplicated strings.
-func uniqueVars(s1, s2 []string) error {
-	for _, v1 := range s1 {
-		for _, v2 := range s2 {
-			if v1 == v2 {
-				return fmt.Errorf("mux: duplicated route variable %q", v2)
-			}
-		}
-	}
-	return nil
-}
-*/
-
 // From Gorilla-Mux
 // mapFromPairs converts variadic string parameters to a string map.
 func mapFromPairs(pairs ...string) (map[string]string, error) {
@@ -2360,7 +2882,7 @@ func matchMap(toCheck map[string]string, toMatch map[string][]string, canonicalK
 	return true
 }
 
-func matchQueryMap(patternMap map[string]string, ps Params) bool {
+func matchQueryMap(patternMap map[string]string, ps *Params) bool {
 	ps.CreateSearch()
 	// fmt.Printf(">>>>>>>>>>>>>>>>> matchQueryMap patternMap=%s ps=%s\n", debug.SVar(patternMap), ps.DumpParam())
 	for i, v := range patternMap {
@@ -2382,6 +2904,10 @@ func IsMapStringBoolEmpty(v map[string]bool) bool {
 	return true
 }
 
+func (n colType) String() string {
+	return dumpCType(n)
+}
+
 func dumpCType(n colType) (rv string) {
 	rv = "("
 	com := ""
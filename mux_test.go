@@ -97,22 +97,23 @@ func Test_SimpleRoute01(t *testing.T) {
 			x.Methods("GET")
 		}
 	}
-	r.setDefaults()
-	r.buildRoutingTable()
+	rt := newRouteTable(r.routes)
+	rt.setDefaults()
+	rt.buildRoutingTable()
 	if false {
 		r.dumpTest()
 	}
-	if r.routes[0].DPath != "/abc/def" {
+	if rt.routes[0].DPath != "/abc/def" {
 		t.Errorf("Expected /abc/def\n")
 	}
-	if len(r.routes[0].DMethods) != 2 {
-		t.Errorf("Expected 2, got %d == %s\n", len(r.routes[0].DMethods), debug.SVar(r.routes[0].DMethods))
+	if len(rt.routes[0].DMethods) != 2 {
+		t.Errorf("Expected 2, got %d == %s\n", len(rt.routes[0].DMethods), debug.SVar(rt.routes[0].DMethods))
 	}
-	if r.routes[1].DPath != "/abc/ghi" {
+	if rt.routes[1].DPath != "/abc/ghi" {
 		t.Errorf("Expected /abc/def\n")
 	}
-	if len(r.routes[1].DMethods) != 1 {
-		t.Errorf("Expected 1, got %d == %s\n", len(r.routes[1].DMethods), debug.SVar(r.routes[1].DMethods))
+	if len(rt.routes[1].DMethods) != 1 {
+		t.Errorf("Expected 1, got %d == %s\n", len(rt.routes[1].DMethods), debug.SVar(rt.routes[1].DMethods))
 	}
 
 	r.AttachWidget(Before, ParseQueryParams)
@@ -187,21 +188,26 @@ func (m *mockResponseWriter) WriteHeader(int) {}
 // This little dodad outputs info on colisions and routes.  Remember that GET /abc/def is a completely
 // different route than POST /abc/def and will show up in a different lcoaiton in the hash table.
 func (r *MuxRouter) OutputStatusInfo() {
+	rt := r.RouteTable()
+	if rt == nil {
+		fmt.Printf("Routes have not been compiled\n")
+		return
+	}
 	nc := 0
 	fmt.Printf("[%4s] %6s %s\n", "", "cType", ".cType flags")
 	fmt.Printf("%6s %6s %s\n", "------", "------", "---------------------------")
-	for i, v := range r.Hash2Test {
+	for i, v := range rt.Hash2Test {
 		if v > 0 {
-			fmt.Printf("[%4d] 0x%04x %s\n", i, r.LookupResults[v].cType, dumpCType(r.LookupResults[v].cType))
-			if (r.LookupResults[v].cType & MultiUrl) != 0 {
+			fmt.Printf("[%4d] 0x%04x %s\n", i, rt.LookupResults[v].cType, dumpCType(rt.LookupResults[v].cType))
+			if (rt.LookupResults[v].cType & MultiUrl) != 0 {
 				nc++
-				for j, w := range r.LookupResults[v].Multi {
+				for j, w := range rt.LookupResults[v].Multi {
 					ns := numChar(j, '/')
 					fmt.Printf("   %2d %s\n", ns, j)
 					_ = w
 				}
 			} else {
-				fmt.Printf("   %2s %s\n", "", r.LookupResults[v].Url)
+				fmt.Printf("   %2s %s\n", "", rt.LookupResults[v].Url)
 			}
 		}
 	}
@@ -239,7 +245,7 @@ var test2017Data = []struct {
 	{true, "GET", "/js/*filename", 21},                    // 21
 	{true, "GET", "/img/*filename", 22},                   // 22
 	{true, "GET", "/css/*filename", 23},                   // 23
-	{true, "GET", "/abc/*p1/:p2", 24},                     // 24 // test with /abc/*p1/:k2 - should be a bad pattern - should not work - should break loop at this point
+	{true, "GET", "/abc/*p1/:p2", 24},                     // 24 // test with /abc/*p1/:k2 - a bad pattern, Error(20043) from ParseRoute
 
 	{true, "GET", "/authorizations", 31},
 	{true, "GET", "/authorizations/:id", 32},
@@ -696,14 +702,7 @@ func init() {
 		}
 	}
 	htx = NewRouter()
-	fmt.Printf("Should generate 3 errors that look like:\n")
-	fmt.Printf("( this is to test the error check code )\n")
-	fmt.Printf("-------------------------------------------------------------------------\n")
-	fmt.Printf("Error(20000): Method %%s is invalid.  Called From: %%s\n")
-	fmt.Printf("Error(20002): Path should begin with '/', passed %%s, File:%%s LinLineNo:%%d\n")
-	fmt.Printf("Error(20002): Path should begin with '/', passed %%s, File:%%s LinLineNo:%%d\n")
-	fmt.Printf("-------------------------------------------------------------------------\n")
-	fmt.Printf("\n")
+	// The bad routes below are reported by htx.Errors(), 20000, 20002 and 20043.
 	htx.AttachWidget(Before, ParseQueryParams)
 	htx.AttachWidget(Before, MethodParam)          // 15ns
 	htx.AttachWidget(Before, ParseBodyAsParams)    // 27ns
@@ -764,8 +763,6 @@ func init() {
 
 	// r.HandleFunc(test.route, rptCalled).Methods("GET")
 
-	htx.setDefaults()
-	htx.buildRoutingTable()
 	htx.CompileRoutes()
 	// htx.OutputStatusInfo()
 }
@@ -1071,8 +1068,9 @@ func Test_1_ServeHTTP(t *testing.T) {
 	url2 := "/abc/:def/:ghi/jkl"
 	Method := "GET"
 	m := (int(Method[0]) + (int(Method[1]) << 1))
-	r.SplitOnSlash3(m, url2, true)
-	rv := r.UrlToCleanRoute("T::T")
+	ms := NewMatchState()
+	r.SplitOnSlash3(ms, m, url2, true)
+	rv := r.UrlToCleanRoute(ms, "T::T")
 	if rv != "/abc/:/:/jkl" {
 		t.Errorf("Test: Expected to have clean pattern\n")
 	}
@@ -1195,8 +1193,6 @@ func Test_2_ServeHTTP(t *testing.T) {
 		ht2.HandleFunc("/5004", createFx(5004)).Methods("POST")
 	}
 
-	ht2.setDefaults()
-	ht2.buildRoutingTable()
 	ht2.CompileRoutes()
 
 	var req http.Request
@@ -1286,7 +1282,7 @@ func Test_2_ServeHTTP(t *testing.T) {
 	m := MethodToCode(Method, 0)
 	// fmt.Printf("m=%d\n", m)
 	Route := "/"
-	r.SplitOnSlash3(m, Route, false)
+	r.SplitOnSlash3(NewMatchState(), m, Route, false)
 }
 
 // -------------------------------------------------------------------------------------------------
@@ -1354,7 +1350,7 @@ func DumpMatchItRan(x MatchItRankType) (rv string) {
 func (r *MuxRouter) DumpRouteData(msg string) {
 	if true {
 		fmt.Printf("\nDumpRouteData: %s\n", msg)
-		for i, v := range r.routeData {
+		for i, v := range r.RouteTable().routeData {
 			if v.MatchItRank != 0 {
 				fmt.Printf("[%03d] ARoute[%d] %s %s 0x%04x MatchItRank=%s\n", i, v.NFxNo, v.Method, v.Route, v.MatchItRank, DumpMatchItRan(v.MatchItRank))
 			} else {
@@ -1376,4 +1372,66 @@ func dumpReArray(tmpRe []Re) (rv string) {
 	return
 }
 
+// -------------------------------------------------------------------------------------------------
+// Build a simple request for the tests.
+func newTestRequest(Method string, Url string) *http.Request {
+	u, err := url.Parse(Url)
+	if err != nil {
+		panic(err)
+	}
+	return &http.Request{
+		Method:     Method,
+		URL:        u,
+		Host:       "localhost:8080",
+		RequestURI: Url,
+		Proto:      "HTTP/1.1",
+		RemoteAddr: "[::1]:53248",
+		Header:     make(http.Header),
+	}
+}
+
+// Two routers in the same process must each have their own pattern tables.
+func Test_IndependentRouters(t *testing.T) {
+	r1 := NewRouter()
+	r1.HandleFunc("/api/:id", createFx(6001)).Methods("GET")
+	r1.HandleFunc("/api/:id/*rest", createFx(6002)).Methods("GET")
+	r1.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	r2 := NewRouter()
+	r2.HandleFunc("/web/page", createFx(6003)).Methods("GET")
+	r2.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	r1.CompileRoutes()
+	r2.CompileRoutes()
+
+	for i, v := range r2.RouteTable().nMatch {
+		for _, p := range v.PatList {
+			if p.Pat != "TT" {
+				t.Errorf("Router 2 has pattern %s at %d from router 1\n", p.Pat, i)
+			}
+		}
+	}
+
+	w := new(mockResponseWriter)
+	tests := []struct {
+		r      *MuxRouter
+		Url    string
+		Expect int
+	}{
+		{r1, "/api/12", 6001},
+		{r1, "/api/12/a", 6002},
+		{r1, "/web/page", -1},
+		{r2, "/web/page", 6003},
+		{r2, "/api/12", -1},
+		{r2, "/api/12/a", -1},
+	}
+	for i, v := range tests {
+		arrived = 0
+		v.r.ServeHTTP(w, newTestRequest("GET", v.Url))
+		if arrived != v.Expect {
+			t.Errorf("Test[%d] %s: Expected %d, got %d\n", i, v.Url, v.Expect, arrived)
+		}
+	}
+}
+
 // func (r *MuxRouter) HostPort_AllRoutes(hp ...string) *MuxRouter {
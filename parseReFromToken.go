@@ -14,7 +14,7 @@ package gogomux
 /* USED */
 // Test: parseReFromToken_test.go
 
-// Possible Improvement - should we validate that name is a name - [a-zA-Z_][a-zA-Z_0-9]* - Maybee?
+// The name is checked by ParseRoute, it has to be [a-zA-Z_][a-zA-Z_0-9]*.
 
 // Pars	{name:Re} into the name and the regular expression.  Indicate with convertToColon==true
 // that this is a {name} pattern that matches /[^/]*/
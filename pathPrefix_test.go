@@ -0,0 +1,67 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_PathPrefix(t *testing.T) {
+	w := new(mockResponseWriter)
+	r := NewRouter()
+	var got Params
+	save := func(n int) HandleFunc {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			arrived = n
+			got = ps.Copy()
+		}
+	}
+	r.PathPrefix("/static/").HandlerFunc(save(9401))
+	r.PathPrefix("/static/css/").HandlerFunc(save(9402))
+	r.PathPrefix("/docs").HandlerFunc(save(9403))
+	r.HandleFunc("/static/index.html", save(9404))
+	r.HandleFunc("/api/:id/*tail", save(9405))
+	r.HandleFunc("/a/b/c/d/e", save(9406))
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	tests := []struct {
+		url    string
+		expect int
+		name   string
+		value  string
+	}{
+		{"/static/", 9401, PathPrefixParam, ""},
+		{"/static/a.js", 9401, PathPrefixParam, "a.js"},
+		{"/static/img/x/y/z.png", 9401, PathPrefixParam, "img/x/y/z.png"},
+		{"/static/css/site.css", 9402, PathPrefixParam, "site.css"},
+		{"/static/css/a/b/c/d/e/f.css", 9402, PathPrefixParam, "a/b/c/d/e/f.css"},
+		{"/static/index.html", 9404, "", ""},
+		{"/static", -1, "", ""},
+		{"/docs", 9403, PathPrefixParam, ""},
+		{"/docs/intro", 9403, PathPrefixParam, "intro"},
+		{"/docsx", -1, "", ""},
+		{"/api/12/a", 9405, "tail", "a"},
+		{"/api/12/a/b/c/d/e/f/g", 9405, "tail", "a/b/c/d/e/f/g"},
+	}
+	for i, test := range tests {
+		arrived = 0
+		got = Params{}
+		r.ServeHTTP(w, newTestRequest("GET", test.url))
+		if arrived != test.expect {
+			t.Errorf("Test %d: %s expected %d, got %d\n", i, test.url, test.expect, arrived)
+			continue
+		}
+		if test.name != "" && got.ByName(test.name) != test.value {
+			t.Errorf("Test %d: %s expected %s=%s, got %s\n", i, test.url, test.name, test.value, got.DumpParam())
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// SegmentKind is the type of one '/' separated part of a route.
+type SegmentKind int
+
+const (
+	SegLiteral  SegmentKind = iota // abc - matched as text
+	SegParam                       // :name - any one word
+	SegVar                         // {name} - any one word, the same as :name
+	SegRegex                       // {name:re} - one word, all of it has to match re or a constraint like {name:int}
+	SegCatchAll                    // *name - the rest of the URL, has to be last
+	SegMixed                       // :name.:ext or v{major}.{minor} - more than one part in a word, see Parts
+)
+
+func (k SegmentKind) String() string {
+	switch k {
+	case SegLiteral:
+		return "literal"
+	case SegParam:
+		return ":param"
+	case SegVar:
+		return "{name}"
+	case SegRegex:
+		return "{name:re}"
+	case SegCatchAll:
+		return "*catchall"
+	case SegMixed:
+		return "mixed"
+	}
+	return fmt.Sprintf("SegmentKind(%d)", int(k))
+}
+
+// RouteSegment is one part of a route from ParseRoute.
+type RouteSegment struct {
+	Kind       SegmentKind
+	Text       string         // The segment as it is in the route, without the '/', a SegLiteral has the '\' escapes removed
+	Name       string         // Name of the param, "" for SegLiteral and SegMixed
+	Re         string         // Regular expression for SegRegex
+	Constraint string         // For SegRegex, set if Re is the name of a constraint, {id:int}, see AddConstraint
+	Pos        int            // Offset of Text in the route
+	Parts      []RouteSegment // For SegMixed the literals and params in the word, a *catchall can not be one
+}
+
+// PatternError is an error in a route, Pos is the offset in the route where it is.
+type PatternError struct {
+	Route string
+	Pos   int
+	Msg   string
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("%s at position %d in %s", e.Msg, e.Pos, e.Route)
+}
+
+// Names of params, the same as a Go identifier.
+var validParamName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z_0-9]*$`)
+
+// ParseRoute splits a route into segments.  The route has to start with a '/'.  Param
+// names have to be [a-zA-Z_][a-zA-Z_0-9]* and can only be used once, the regular
+// expressions have to compile and can not have a '/' in them, and a *catchall has to
+// be the last segment.  A route that ends in '/' has an empty SegLiteral at the end, that
+// is the only empty segment allowed.  A '\' makes the next character literal, so
+// /ns/\:system and /odata/\{set} match the text ":system" and "{set}".
+//
+// A segment can have more than one param, /files/:name.:ext is a SegMixed with the
+// parts :name, "." and :ext.  A :name ends at the first character that can not be in
+// a name.
+func ParseRoute(route string) (segs []RouteSegment, err error) {
+	if route == "" || route[0] != '/' {
+		return nil, &PatternError{Route: route, Pos: 0, Msg: "route has to start with '/'"}
+	}
+	seen := make(map[string]bool)
+	for start := 1; start <= len(route); {
+		if len(segs) > 0 && segs[len(segs)-1].Kind == SegCatchAll {
+			return nil, patternError(route, segs[len(segs)-1].Pos, "*%s has to be the last segment", segs[len(segs)-1].Name)
+		}
+		parts, end, pe := parseSegment(route, start)
+		if pe != nil {
+			return nil, pe
+		}
+		s := RouteSegment{Kind: SegLiteral, Pos: start}
+		switch len(parts) {
+		case 0:
+			if end < len(route) {
+				return nil, patternError(route, start, "empty segment")
+			}
+		case 1:
+			s = parts[0]
+		default:
+			s.Kind, s.Text, s.Parts = SegMixed, route[start:end], parts
+		}
+		for _, p := range append([]RouteSegment{s}, s.Parts...) {
+			if p.Kind == SegLiteral || p.Kind == SegMixed {
+				continue
+			}
+			if p.Kind == SegCatchAll && s.Kind == SegMixed {
+				return nil, patternError(route, p.Pos, "*%s has to be all of a segment", p.Name)
+			}
+			if !validParamName.MatchString(p.Name) {
+				return nil, patternError(route, p.Pos+1, "invalid param name %q", p.Name)
+			}
+			if seen[p.Name] {
+				return nil, patternError(route, p.Pos+1, "duplicate param name %q", p.Name)
+			}
+			seen[p.Name] = true
+		}
+		segs = append(segs, s)
+		start = end + 1
+	}
+	return segs, nil
+}
+
+func patternError(route string, pos int, format string, args ...interface{}) *PatternError {
+	return &PatternError{Route: route, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Split the segment that starts at start into literals and params, end is the offset of
+// the '/' after it or len(route).
+func parseSegment(route string, start int) (parts []RouteSegment, end int, pe *PatternError) {
+	var lit []byte
+	litPos := start
+	flush := func() {
+		if len(lit) > 0 {
+			parts = append(parts, RouteSegment{Kind: SegLiteral, Text: string(lit), Pos: litPos})
+			lit = lit[:0]
+		}
+	}
+	i := start
+	for i < len(route) && route[i] != '/' {
+		switch c := route[i]; c {
+		case '\\':
+			if i+1 >= len(route) || route[i+1] == '/' {
+				return nil, i, patternError(route, i, "'\\' has to be followed by a character")
+			}
+			if len(lit) == 0 {
+				litPos = i
+			}
+			lit = append(lit, route[i+1])
+			i += 2
+		case '{':
+			flush()
+			j, d := i, 0
+			for ; j < len(route); j++ {
+				if route[j] == '{' {
+					d++
+				} else if route[j] == '}' {
+					d--
+					if d == 0 {
+						break
+					}
+				} else if route[j] == '/' {
+					return nil, j, patternError(route, j, "'/' is not allowed in {}")
+				}
+			}
+			if j >= len(route) {
+				return nil, j, patternError(route, i, "missing '}'")
+			}
+			text := route[i : j+1]
+			name, re, _, conv := parseReFromToken3(text)
+			p := RouteSegment{Kind: SegVar, Text: text, Name: name, Pos: i}
+			if !conv {
+				p.Kind, p.Re = SegRegex, re
+				if re == "" {
+					return nil, j, patternError(route, i, "missing regular expression in %s", text)
+				}
+				if _, e := regexp.Compile(re); e != nil {
+					return nil, j, patternError(route, i+len(name)+2, "invalid regular expression for %s, %s", name, e)
+				}
+				if validParamName.MatchString(re) {
+					p.Constraint = re
+				}
+			}
+			parts = append(parts, p)
+			i = j + 1
+		case ':', '*':
+			if c == ':' && len(lit) > 0 && isNameChar(lit[len(lit)-1]) { // users:batchGet is a literal
+				lit = append(lit, c)
+				i++
+				continue
+			}
+			flush()
+			j := i + 1
+			for j < len(route) && route[j] != '/' && (c == '*' || isNameChar(route[j])) {
+				j++
+			}
+			p := RouteSegment{Kind: SegParam, Text: route[i:j], Name: route[i+1 : j], Pos: i}
+			if c == '*' {
+				p.Kind = SegCatchAll
+			}
+			parts = append(parts, p)
+			i = j
+		default:
+			if len(lit) == 0 {
+				litPos = i
+			}
+			lit = append(lit, c)
+			i++
+		}
+	}
+	flush()
+	return parts, i, nil
+}
+
+// True if c can be in a param name, a :name ends at the first c that can not.  A ':'
+// after one of these in a literal is text, not a param.
+func isNameChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// The route as it is matched against a URL, the literals without the escapes.
+func segsPath(segs []RouteSegment) string {
+	p := ""
+	for _, s := range segs {
+		p += "/" + s.Text
+	}
+	return p
+}
@@ -0,0 +1,219 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_ParseRoute(t *testing.T) {
+	tests := []struct {
+		route string
+		kinds []SegmentKind
+		names []string
+	}{
+		{"/", []SegmentKind{SegLiteral}, []string{""}},
+		{"/abc/def", []SegmentKind{SegLiteral, SegLiteral}, []string{"", ""}},
+		{"/abc/", []SegmentKind{SegLiteral, SegLiteral}, []string{"", ""}},
+		{"/api/:id/*rest", []SegmentKind{SegLiteral, SegParam, SegCatchAll}, []string{"", "id", "rest"}},
+		{"/u/{name}", []SegmentKind{SegLiteral, SegVar}, []string{"", "name"}},
+		{"/rc/{id:^[0-9][0-9]*$}", []SegmentKind{SegLiteral, SegRegex}, []string{"", "id"}},
+		{"/r2/{blah:[1-9]}/:goo", []SegmentKind{SegLiteral, SegRegex, SegParam}, []string{"", "blah", "goo"}},
+		{"/n/{d:[0-9]{2}}", []SegmentKind{SegLiteral, SegRegex}, []string{"", "d"}},
+	}
+	for i, test := range tests {
+		segs, err := ParseRoute(test.route)
+		if err != nil {
+			t.Errorf("Test[%d] %s: unexpected error %s", i, test.route, err)
+			continue
+		}
+		if len(segs) != len(test.kinds) {
+			t.Errorf("Test[%d] %s: expected %d segments, got %d", i, test.route, len(test.kinds), len(segs))
+			continue
+		}
+		for j, s := range segs {
+			if s.Kind != test.kinds[j] || s.Name != test.names[j] {
+				t.Errorf("Test[%d] %s: segment %d expected %s %q, got %s %q", i, test.route, j, test.kinds[j], test.names[j], s.Kind, s.Name)
+			}
+			if test.route[s.Pos:s.Pos+len(s.Text)] != s.Text {
+				t.Errorf("Test[%d] %s: segment %d Pos %d is not %q", i, test.route, j, s.Pos, s.Text)
+			}
+		}
+	}
+	segs, _ := ParseRoute("/n/{d:[0-9]{2}}")
+	if segs[1].Re != "[0-9]{2}" {
+		t.Errorf("Expected regular expression [0-9]{2}, got %q", segs[1].Re)
+	}
+}
+
+func Test_ParseRouteErrors(t *testing.T) {
+	tests := []struct {
+		route string
+		pos   int
+		msg   string
+	}{
+		{"abc", 0, "start with '/'"},
+		{"/abc/*p1/:p2", 5, "has to be the last"},
+		{"/a/:id/b/:id", 10, "duplicate param name"},
+		{"/a/:id/{id:[0-9]+}", 8, "duplicate param name"},
+		{"/a/:", 4, "invalid param name"},
+		{"/a/:1x", 4, "invalid param name"},
+		{"/a/*my-rest", 4, "invalid param name"},
+		{"/a/{na me}", 4, "invalid param name"},
+		{"/a/{id:[a/b]}", 9, "'/' is not allowed"},
+		{"/a/{id:[0-9]+", 3, "missing '}'"},
+		{"/a/:id.*rest", 7, "has to be all of a segment"},
+		{"/a/:id.:id", 8, "duplicate param name"},
+		{"/a/{id:[0-9}", 7, "invalid regular expression"},
+		{"/a//b", 3, "empty segment"},
+	}
+	for i, test := range tests {
+		_, err := ParseRoute(test.route)
+		pe, ok := err.(*PatternError)
+		if !ok {
+			t.Errorf("Test[%d] %s: expected a *PatternError, got %v", i, test.route, err)
+			continue
+		}
+		if pe.Pos != test.pos || !strings.Contains(pe.Msg, test.msg) {
+			t.Errorf("Test[%d] %s: expected %q at %d, got %q at %d", i, test.route, test.msg, test.pos, pe.Msg, pe.Pos)
+		}
+	}
+}
+
+func Test_ParseRouteCompile(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/ok/:id", createFx(9801)).Methods("GET")
+	r.HandleFunc("/bad/:id/:id", createFx(9802)).Methods("GET")
+	err := r.CompileRoutes()
+	re, ok := err.(RouteErrors)
+	if !ok || len(re) != 1 || re[0].Code != 20043 || re[0].Pattern != "/bad/:id/:id" {
+		t.Errorf("Expected one Error(20043) for /bad/:id/:id, got %v", err)
+	}
+}
+
+func Test_ParseRouteEscape(t *testing.T) {
+	segs, err := ParseRoute(`/ns/\:system/\*/\{set}/a\\b/:id`)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expect := []string{"ns", ":system", "*", "{set}", `a\b`, ":id"}
+	for i, s := range segs {
+		if s.Text != expect[i] || (i < 5 && s.Kind != SegLiteral) {
+			t.Errorf("Segment %d: expected literal %q, got %s %q", i, expect[i], s.Kind, s.Text)
+		}
+	}
+	if segs[5].Kind != SegParam {
+		t.Errorf("Expected :id to be a param, got %s", segs[5].Kind)
+	}
+	if _, err := ParseRoute(`/ns/abc\`); err == nil {
+		t.Errorf("Expected an error for a '\\' at the end")
+	}
+
+	r := NewRouter()
+	var got Params
+	save := func(ii int) HandleFunc {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			arrived, got = ii, ps.Copy()
+		}
+	}
+	r.HandleFunc(`/ns/\:system`, save(9811)).Methods("GET")
+	r.HandleFunc("/ns/:name", save(9812)).Methods("GET")
+	r.HandleFunc(`/odata/\{set}`, save(9813)).Methods("GET")
+	r.HandleFunc("/odata/{name}", save(9814)).Methods("GET")
+	r.HandleFunc(`/all/\*`, save(9815)).Methods("GET")
+	if err := r.CompileRoutes(); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	tests := []struct {
+		url    string
+		expect int
+		name   string
+	}{
+		{"/ns/:system", 9811, ""},
+		{"/ns/system", 9812, "system"},
+		{"/ns/:other", 9812, ":other"},
+		{"/odata/%7Bset%7D", 9813, ""},
+		{"/odata/set", 9814, "set"},
+		{"/all/*", 9815, ""},
+		{"/all/x", -1, ""},
+	}
+	for i, test := range tests {
+		arrived, got = -1, Params{}
+		r.ServeHTTP(new(mockResponseWriter), newTestRequest("GET", test.url))
+		if arrived != test.expect {
+			t.Errorf("Test[%d] %s: expected %d, got %d", i, test.url, test.expect, arrived)
+		} else if test.name != "" && got.ByName("name") != test.name {
+			t.Errorf("Test[%d] %s: expected name=%s, got %s", i, test.url, test.name, got.DumpParam())
+		}
+	}
+	u, err := r.HandleFunc(`/lit/\{x}/:id`, save(9816)).URLPath("id", "7")
+	if err != nil || u.Path != "/lit/{x}/7" {
+		t.Errorf("Expected /lit/{x}/7, got %v %v", u, err)
+	}
+}
+
+func Test_ParseRouteColon(t *testing.T) {
+	tests := []struct {
+		route string
+		kind  SegmentKind
+		text  string
+	}{
+		{"/v1/users:batchGet", SegLiteral, "users:batchGet"},
+		{"/v1/a_1:b", SegLiteral, "a_1:b"},
+		{"/v1/x.:id", SegMixed, "x.:id"},
+		{`/v1/{res}\:batchGet`, SegMixed, `{res}\:batchGet`},
+	}
+	for i, test := range tests {
+		segs, err := ParseRoute(test.route)
+		if err != nil {
+			t.Errorf("Test[%d] %s: unexpected error %s", i, test.route, err)
+			continue
+		}
+		if s := segs[1]; s.Kind != test.kind || s.Text != test.text {
+			t.Errorf("Test[%d] %s: expected %s %q, got %s %q", i, test.route, test.kind, test.text, s.Kind, s.Text)
+		}
+	}
+
+	r := NewRouter()
+	var got Params
+	save := func(ii int) HandleFunc {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			arrived, got = ii, ps.Copy()
+		}
+	}
+	r.HandleFunc("/v1/users:batchGet", save(9821)).Methods("POST")
+	r.HandleFunc("/v1/users/:id", save(9822)).Methods("POST")
+	r.HandleFunc(`/v2/{res}\:batchGet`, save(9823)).Methods("POST")
+	if err := r.CompileRoutes(); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	for i, test := range []struct {
+		url    string
+		expect int
+		res    string
+	}{
+		{"/v1/users:batchGet", 9821, ""},
+		{"/v1/users:other", -1, ""},
+		{"/v1/users/7", 9822, ""},
+		{"/v2/groups:batchGet", 9823, "groups"},
+		{"/v2/groups", -1, ""},
+	} {
+		arrived, got = -1, Params{}
+		r.ServeHTTP(new(mockResponseWriter), newTestRequest("POST", test.url))
+		if arrived != test.expect {
+			t.Errorf("Test[%d] %s: expected %d, got %d", i, test.url, test.expect, arrived)
+		} else if test.res != "" && got.ByName("res") != test.res {
+			t.Errorf("Test[%d] %s: expected res=%s, got %s", i, test.url, test.res, got.DumpParam())
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_UseRawPath(t *testing.T) {
+	var got, gotRest string
+	r := NewRouter()
+	r.HandleFunc("/files/:name/info", func(w http.ResponseWriter, req *http.Request, ps Params) {
+		arrived = 9301
+		got = ps.ByName("name")
+	}).Methods("GET")
+	r.HandleFunc("/blob/*rest", func(w http.ResponseWriter, req *http.Request, ps Params) {
+		arrived = 9302
+		gotRest = ps.ByName("rest")
+	}).Methods("GET")
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	// Without UseRawPath the %2F is a slash
+	r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", "/files/a%2Fb/info"))
+	if arrived != -1 {
+		t.Errorf("Expected not found without UseRawPath, got %d\n", arrived)
+	}
+
+	r.UseRawPath = true
+	var rawTests = []struct {
+		Url     string
+		Arrived int
+		Name    string
+		Rest    string
+	}{
+		{"/files/a%2Fb/info", 9301, "a/b", ""},
+		{"/files/my%20file.txt/info", 9301, "my file.txt", ""},
+		{"/files/plain/info", 9301, "plain", ""},
+		{"/blob/x%2Fy/z%20w", 9302, "", "x/y/z w"},
+	}
+	for i, test := range rawTests {
+		arrived, got, gotRest = 0, "", ""
+		r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", test.Url))
+		if arrived != test.Arrived || got != test.Name || gotRest != test.Rest {
+			t.Errorf("Test %d: %s expected %d ->%s<- ->%s<- got %d ->%s<- ->%s<-\n", i, test.Url, test.Arrived, test.Name, test.Rest, arrived, got, gotRest)
+		}
+	}
+
+	rm, found := r.Match(newTestRequest("GET", "/files/a%2Fb/info"))
+	if !found || rm.Params.ByName("name") != "a/b" {
+		t.Errorf("Expected Match to find name a/b\n")
+	}
+}
@@ -0,0 +1,183 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_TrailingSlash(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/docs/", createFx(9131)).Methods("GET")
+	r.HandleFunc("/api/:id", createFx(9132)).Methods("GET")
+	r.HandleFunc("/a/b/", createFx(9133)).Methods("GET")
+	r.HandleFunc("/static/*rest", createFx(9134)).Methods("GET")
+	r.HandleFunc("/:name", createFx(9135)).Methods("GET")
+
+	for i, test := range []struct {
+		url    string
+		expect int
+	}{
+		{"/docs", 9131},
+		{"/docs/", 9131},
+		{"/api/12", 9132},
+		{"/api/12/", 9132},
+		{"/a/b", 9133},
+		{"/a//b/", 9133},
+		{"/a/./b/", 9133},
+		{"/static/", 9134},
+		{"/static/x/", 9134},
+		{"/x/", 9135},
+	} {
+		arrived = 0
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, newTestRequest("GET", test.url))
+		if arrived != test.expect {
+			t.Errorf("Test %d: %s expected %d without StrictSlash, got %d %d\n", i, test.url, test.expect, rec.Code, arrived)
+		}
+	}
+}
+
+func Test_RedirectTrailingSlash(t *testing.T) {
+	r := NewRouter()
+	r.StrictSlash = true
+	r.HandleFunc("/docs/", createFx(9101)).Methods("GET")
+	r.HandleFunc("/api/:id", createFx(9102)).Methods("GET", "POST")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/docs"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without UseRedirect, got %d\n", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/api/12/"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for /api/12/, it is not the same as /docs/, got %d\n", rec.Code)
+	}
+	arrived = 0
+	r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", "/docs/"))
+	if arrived != 9101 {
+		t.Errorf("Expected 9101 for /docs/, got %d\n", arrived)
+	}
+
+	r.UseRedirect = true
+	var redirectTests = []struct {
+		Method   string
+		Url      string
+		Code     int
+		Location string
+	}{
+		{"GET", "/docs", http.StatusMovedPermanently, "/docs/"},
+		{"GET", "/api/12/", http.StatusMovedPermanently, "/api/12"},
+		{"POST", "/api/12/", http.StatusPermanentRedirect, "/api/12"},
+		{"GET", "/api/12/?x=1", http.StatusMovedPermanently, "/api/12?x=1"},
+		{"GET", "/nothing/", http.StatusNotFound, ""},
+	}
+	for i, test := range redirectTests {
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, newTestRequest(test.Method, test.Url))
+		if rec.Code != test.Code {
+			t.Errorf("Test %d: %s %s expected %d got %d\n", i, test.Method, test.Url, test.Code, rec.Code)
+		}
+		if loc := rec.Header().Get("Location"); loc != test.Location {
+			t.Errorf("Test %d: %s %s expected Location ->%s<- got ->%s<-\n", i, test.Method, test.Url, test.Location, loc)
+		}
+	}
+}
+
+func Test_RedirectTrailingSlashOneWord(t *testing.T) {
+	r := NewRouter()
+	r.StrictSlash = true
+	r.HandleFunc("/:name", createFx(9121)).Methods("GET")
+
+	for i, url := range []string{"/a/", "/a/b/c"} {
+		rec := httptest.NewRecorder()
+		arrived = 0
+		r.ServeHTTP(rec, newTestRequest("GET", url))
+		if arrived != 0 || rec.Code != http.StatusNotFound {
+			t.Errorf("Test %d: expected 404 for %s, got %d arrived=%d\n", i, url, rec.Code, arrived)
+		}
+	}
+
+	r.UseRedirect = true
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/a/"))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/a" {
+		t.Errorf("Expected 301 to /a, got %d %s\n", rec.Code, rec.Header().Get("Location"))
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/a/b/c"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for /a/b/c, got %d\n", rec.Code)
+	}
+}
+
+func Test_RedirectFixed(t *testing.T) {
+	r := NewRouter()
+	r.StrictSlash = true
+	r.HandleFunc("/a/b", createFx(9111)).Methods("GET")
+	r.HandleFunc("/a/c/", createFx(9113)).Methods("GET")
+
+	for _, url := range []string{"/a//c/", "/a/./c/"} {
+		arrived = 0
+		r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", url))
+		if arrived != 9113 {
+			t.Errorf("Expected %s to be served by 9113, got %d\n", url, arrived)
+		}
+	}
+
+	arrived = 0
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/a//b"))
+	if arrived != 9111 {
+		t.Errorf("Expected /a//b to be served by 9111 without RedirectFixed, got %d\n", arrived)
+	}
+
+	r.RedirectFixed = true
+	arrived = 0
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/a/./x/../b"))
+	if arrived != 0 || rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/a/b" {
+		t.Errorf("Expected 301 to /a/b, got %d %s arrived=%d\n", rec.Code, rec.Header().Get("Location"), arrived)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", "/a/b"))
+	if arrived != 9111 {
+		t.Errorf("Expected 9111, got %d\n", arrived)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/a//c/"))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/a/c/" {
+		t.Errorf("Expected 301 to /a/c/, got %d %s\n", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func Test_FixedPathParams(t *testing.T) {
+	r := NewRouter()
+	var got Params
+	r.HandleFunc("/api/:id/:name", func(w http.ResponseWriter, req *http.Request, ps Params) {
+		arrived, got = 9112, ps.Copy()
+	}).Methods("GET")
+
+	for i, url := range []string{"/api//12/bob", "/api/./12/bob", "/api/x/../12/bob", "/api/12//./bob"} {
+		arrived, got = 0, Params{}
+		r.ServeHTTP(httptest.NewRecorder(), newTestRequest("GET", url))
+		if arrived != 9112 || got.ByName("id") != "12" || got.ByName("name") != "bob" {
+			t.Errorf("Test %d: %s expected id=12 name=bob, got %d %s\n", i, url, arrived, got.DumpParam())
+		}
+		_, ps, _, ok := r.Lookup("GET", url)
+		if !ok || ps.ByName("id") != "12" || ps.ByName("name") != "bob" {
+			t.Errorf("Test %d: Lookup %s expected id=12 name=bob, got %v %s\n", i, url, ok, ps.DumpParam())
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Get returns the route with the name set by Name(), nil if there is none.  If more
+// than one route has the name the last one named is returned.
+//
+//	r.HandleFunc("/articles/:category/{id:[0-9]+}", article).Name("article")
+//	u, err := r.Get("article").URL("category", "technology", "id", "42")
+func (r *MuxRouter) Get(name string) *ARoute {
+	r.compileLock.Lock()
+	defer r.compileLock.Unlock()
+	return r.names[name]
+}
+
+// Add a route to the names, old is the name it had before.
+func (r *MuxRouter) setName(route *ARoute, old string) {
+	r.compileLock.Lock()
+	defer r.compileLock.Unlock()
+	if r.names == nil {
+		r.names = make(map[string]*ARoute)
+	}
+	if old != "" && r.names[old] == route {
+		delete(r.names, old)
+	}
+	if route.DName != "" {
+		r.names[route.DName] = route
+	}
+}
+
+// Build the names from the routes, used when the routes are replaced.  The caller has
+// the compileLock.
+func (r *MuxRouter) resetNames() {
+	r.names = make(map[string]*ARoute)
+	for _, v := range r.routes {
+		if v.DName != "" {
+			r.names[v.DName] = v
+		}
+	}
+}
+
+// URL builds a URL for the route from name, value pairs.  The host and scheme are
+// set if the route, or the Subrouter it is in, has a host.
+func (r *ARoute) URL(pairs ...string) (*url.URL, error) {
+	u, err := r.URLPath(pairs...)
+	if err != nil {
+		return nil, err
+	}
+	if v := r.inherited(); v.DHost != "" || v.DHostPort != "" {
+		h, err := r.URLHost(pairs...)
+		if err != nil {
+			return nil, err
+		}
+		u.Scheme, u.Host = h.Scheme, h.Host
+	}
+	return u, nil
+}
+
+// URLHost builds the scheme and host for the route from name, value pairs.  The
+// scheme is https if that is the only scheme the route has.
+func (r *ARoute) URLHost(pairs ...string) (*url.URL, error) {
+	values, err := urlValues(r, pairs)
+	if err != nil {
+		return nil, err
+	}
+	v := r.inherited()
+	var host string
+	switch {
+	case v.DHostPort != "":
+		host, err = buildHost(v.DHostPort, values)
+	case v.DHost != "":
+		host, err = buildHost(v.DHost, values)
+		if err == nil && v.DPort != "" {
+			var port string
+			port, err = buildHost(v.DPort, values)
+			host += ":" + port
+		}
+	default:
+		err = fmt.Errorf("gogomux: route %q has no host", v.DName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	scheme := "http"
+	if _, http, https := isHttpHttps(v.DSchemes); https && !http {
+		scheme = "https"
+	}
+	return &url.URL{Scheme: scheme, Host: host}, nil
+}
+
+// URLPath builds the path for the route from name, value pairs.  Each value has to
+// match the {name:re} for it.  A *name at the end can be left out.
+func (r *ARoute) URLPath(pairs ...string) (*url.URL, error) {
+	values, err := urlValues(r, pairs)
+	if err != nil {
+		return nil, err
+	}
+	v := r.inherited()
+	paths := routePaths(&v)
+	tpl := paths[len(paths)-1]
+	if len(paths) > 1 && values[PathPrefixParam] == "" { // /docs with no rest
+		tpl = paths[0]
+	}
+	segs, err := ParseRoute(tpl)
+	if err != nil {
+		return nil, fmt.Errorf("gogomux: route %q: %s", v.DName, err)
+	}
+	raw, escaped := []string{""}, []string{""}
+	for _, seg := range segs {
+		switch seg.Kind {
+		case SegCatchAll:
+			val := values[seg.Name]
+			raw = append(raw, val)
+			escaped = append(escaped, escapePath(val))
+			continue
+		case SegLiteral:
+			raw = append(raw, seg.Text)
+			escaped = append(escaped, url.PathEscape(seg.Text))
+			continue
+		}
+		parts := []RouteSegment{seg}
+		if seg.Kind == SegMixed {
+			parts = seg.Parts
+		}
+		word := ""
+		for _, p := range parts {
+			if p.Kind == SegLiteral {
+				word += p.Text
+				continue
+			}
+			val, err := r.urlValue(p, values)
+			if err != nil {
+				return nil, err
+			}
+			word += val
+		}
+		raw = append(raw, word)
+		escaped = append(escaped, url.PathEscape(word))
+	}
+	return &url.URL{Path: strings.Join(raw, "/"), RawPath: strings.Join(escaped, "/")}, nil
+}
+
+// The value for a param, it has to match the {name:re} for it and only a *catchall
+// can be empty.
+func (r *ARoute) urlValue(p RouteSegment, values map[string]string) (string, error) {
+	val, ok := values[p.Name]
+	if !ok {
+		return "", fmt.Errorf("gogomux: route %q: missing value for %s", r.DName, p.Name)
+	}
+	if val == "" && p.Kind != SegCatchAll { // It would not match, /articles//42
+		return "", fmt.Errorf("gogomux: route %q: empty value for %s", r.DName, p.Name)
+	}
+	if p.Kind == SegRegex {
+		cre, err := r.parent.segmentRe(p)
+		if err != nil {
+			return "", fmt.Errorf("gogomux: route %q: %s", r.DName, err)
+		}
+		if !cre.MatchString(val) {
+			return "", fmt.Errorf("gogomux: route %q: value %q for %s does not match %s", r.DName, val, p.Name, p.Re)
+		}
+	}
+	return val, nil
+}
+
+// The route with what it gets from its Subrouter, if any.
+func (r *ARoute) inherited() ARoute {
+	v := *r
+	if v.group != nil {
+		inheritFrom(&v, v.group)
+	}
+	return v
+}
+
+// Put the pairs in a map.  r is checked here so that r.Get("x").URL() returns an
+// error when there is no route named "x".
+func urlValues(r *ARoute, pairs []string) (map[string]string, error) {
+	if r == nil {
+		return nil, fmt.Errorf("gogomux: no route with that name")
+	}
+	if len(pairs)%2 == 1 {
+		return nil, fmt.Errorf("gogomux: route %q: values must be pairs of name, value", r.DName)
+	}
+	values := make(map[string]string)
+	for i := 0; i < len(pairs); i += 2 {
+		values[pairs[i]] = pairs[i+1]
+	}
+	return values, nil
+}
+
+// Escape each part of a *name value, the '/' are kept.
+func escapePath(s string) string {
+	parts := strings.Split(s, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// Fill in the variables in a host template, the result has to match the template.
+func buildHost(tpl string, values map[string]string) (string, error) {
+	ht, err := compileHostTemplate(tpl)
+	if err != nil {
+		return "", err
+	}
+	if ht.re == nil {
+		return tpl, nil
+	}
+	parts, _ := scanHostTemplate(tpl) // Checked by compileHostTemplate
+	s := ""
+	for _, p := range parts {
+		switch {
+		case p.name != "":
+			val, ok := values[p.name]
+			if !ok {
+				return "", fmt.Errorf("gogomux: missing value for %s in host %s", p.name, tpl)
+			}
+			s += val
+		case p.re != "":
+			return "", fmt.Errorf("gogomux: can not build a host from %s, it has a '*'", tpl)
+		default:
+			s += p.text
+		}
+	}
+	if !ht.re.MatchString(s) {
+		return "", fmt.Errorf("gogomux: host %s does not match %s", s, tpl)
+	}
+	return s, nil
+}
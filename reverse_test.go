@@ -0,0 +1,87 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import "testing"
+
+func Test_ReverseRouting(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/articles/:category/{id:[0-9]+}", createFx(9501)).Name("article")
+	r.HandleFunc("/files/*path", createFx(9502)).Name("file")
+	r.PathPrefix("/docs").HandlerFunc(createFx(9503)).Name("docs")
+	s := r.Host("{subdomain:[a-z]+}.domain.com").Schemes("https").PathPrefix("/news").Subrouter()
+	s.HandleFunc("/:id", createFx(9504)).Name("news")
+	r.HandleFunc("/old", createFx(9505)).Name("renamed").Name("new")
+
+	tests := []struct {
+		name   string
+		pairs  []string
+		expect string
+		isErr  bool
+	}{
+		{"article", []string{"category", "technology", "id", "42"}, "/articles/technology/42", false},
+		{"article", []string{"category", "a b/c", "id", "42"}, "/articles/a%20b%2Fc/42", false},
+		{"article", []string{"category", "technology", "id", "x42"}, "", true},
+		{"article", []string{"category", "technology"}, "", true},
+		{"article", []string{"category"}, "", true},
+		{"article", []string{"category", "", "id", "42"}, "", true}, // Not /articles//42
+		{"file", []string{"path", ""}, "/files/", false},
+		{"file", []string{"path", "a/b c.txt"}, "/files/a/b%20c.txt", false},
+		{"file", nil, "/files/", false},
+		{"docs", nil, "/docs", false},
+		{"docs", []string{PathPrefixParam, "intro"}, "/docs/intro", false},
+		{"news", []string{"subdomain", "sports", "id", "7"}, "https://sports.domain.com/news/7", false},
+		{"news", []string{"subdomain", "Sports", "id", "7"}, "", true},
+		{"new", nil, "/old", false},
+		{"renamed", nil, "", true},
+		{"nope", nil, "", true},
+	}
+	for i, test := range tests {
+		u, err := r.Get(test.name).URL(test.pairs...)
+		if test.isErr {
+			if err == nil {
+				t.Errorf("Test %d: %s expected an error, got %s\n", i, test.name, u)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: %s unexpected error %s\n", i, test.name, err)
+		} else if u.String() != test.expect {
+			t.Errorf("Test %d: %s expected %s, got %s\n", i, test.name, test.expect, u)
+		}
+	}
+
+	h, err := r.Get("news").URLHost("subdomain", "sports")
+	if err != nil || h.String() != "https://sports.domain.com" {
+		t.Errorf("Expected https://sports.domain.com, got %s %v\n", h, err)
+	}
+	if _, err = r.Get("article").URLHost(); err == nil {
+		t.Errorf("Expected an error for URLHost on a route with no host\n")
+	}
+	r.HandleFunc("/x", createFx(9506)).Host("{sub:[a-z]{2}}.example.com").Name("two")
+	if h, err := r.Get("two").URL("sub", "ab"); err != nil || h.String() != "http://ab.example.com/x" {
+		t.Errorf("Expected http://ab.example.com/x, got %s %v\n", h, err)
+	}
+	if _, err = r.Get("two").URL("sub", "abc"); err == nil {
+		t.Errorf("Expected an error for sub=abc\n")
+	}
+	p, err := r.Get("news").URLPath("id", "7")
+	if err != nil || p.String() != "/news/7" {
+		t.Errorf("Expected /news/7, got %s %v\n", p, err)
+	}
+
+	if err = r.RemoveRoute("article"); err != nil {
+		t.Errorf("Unexpected error %s\n", err)
+	}
+	if r.Get("article") != nil {
+		t.Errorf("Expected no route after RemoveRoute\n")
+	}
+}
@@ -0,0 +1,83 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RouteError is a problem with a route found when it is registered or compiled.  Code
+// is the number that is shown as Error(200xx), and what is done with the route:
+//
+//	20000	Invalid method, scheme or protocal - the value is left out
+//	20002	Path does not begin with '/' - the route is left out of the table
+//	20003	Invalid method - the route is left out for that method
+//	20012	Headers are not name, value pairs - the headers are not checked
+//	20018	Queries are not name, value pairs - the queries are not checked
+//	20040	Invalid host template - the host is compared as text
+//	20041	Invalid {name:re} value in Headers or Queries - the value is compared as text
+//	20042	Duplicate, equivalent or shadowed route, see RouteConflict - the route is kept
+//	20043	Invalid path, see PatternError - the route is left out of the table
+type RouteError struct {
+	Code     int    // Error(Code)
+	Msg      string // What is wrong
+	Pattern  string // The path, method or value that has the problem
+	FileName string // Where the route was defined
+	LineNo   int    //
+}
+
+func (e *RouteError) Error() string {
+	return fmt.Sprintf("Error(%d): %s, Pattern: %s FileName: %s LineNo: %d", e.Code, e.Msg, e.Pattern, e.FileName, e.LineNo)
+}
+
+// RouteErrors is the error returned by CompileRoutes, all of the problems found.
+type RouteErrors []*RouteError
+
+func (e RouteErrors) Error() string {
+	s := make([]string, 0, len(e))
+	for _, v := range e {
+		s = append(s, v.Error())
+	}
+	return strings.Join(s, "\n")
+}
+
+func newRouteError(code int, msg, pattern, fn string, ln int) *RouteError {
+	return &RouteError{Code: code, Msg: msg, Pattern: pattern, FileName: fn, LineNo: ln}
+}
+
+// Keep an error found while building the table.
+func (t *RouteTable) addError(e *RouteError) {
+	if e != nil {
+		t.errors = append(t.errors, e)
+	}
+}
+
+// Keep an error found when a route is registered, it is added to the table each time
+// the routes are compiled.
+func (r *ARoute) addError(code int, msg, pattern string) {
+	r.errors = append(r.errors, newRouteError(code, msg, pattern, r.FileName, r.LineNo))
+}
+
+// Errors returns the problems found when the table was built, and when the routes in it
+// were registered.
+func (t *RouteTable) Errors() []*RouteError {
+	return t.errors
+}
+
+// Errors returns the problems found with the routes, they are compiled first if they
+// have changed.  See CompileRoutes.
+func (r *MuxRouter) Errors() []*RouteError {
+	r.CompileRoutes()
+	r.compileLock.Lock()
+	defer r.compileLock.Unlock()
+	return r.checkedTable().Errors()
+}
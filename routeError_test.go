@@ -0,0 +1,81 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import "testing"
+
+func Test_RouteErrors(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/ok/:id", createFx(9701))
+	bad := []*ARoute{
+		r.HandleFunc("/re/{id:[0-9}", createFx(9702)),
+		r.HandleFunc("/m", createFx(9703)).Methods("get"),
+		r.HandleFunc("no-slash", createFx(9704)),
+		r.HandleFunc("/q", createFx(9705)).Queries("a"),
+		r.HandleFunc("/h", createFx(9706)).Host("{sub.example.com"),
+		r.HandleFunc("/v", createFx(9707)).Headers("X-A", "{a:(}"),
+	}
+	codes := []int{20043, 20000, 20002, 20018, 20040, 20041}
+
+	err := r.CompileRoutes() // Must not panic on the bad regular expression
+	errs, ok := err.(RouteErrors)
+	if !ok || len(errs) != len(codes) {
+		t.Fatalf("Expected %d errors, got %v\n", len(codes), err)
+	}
+	found := make(map[int]*RouteError)
+	for _, e := range errs {
+		found[e.Code] = e
+	}
+	for i, code := range codes {
+		e := found[code]
+		if e == nil {
+			t.Errorf("Expected Error(%d)\n", code)
+			continue
+		}
+		if e.FileName != bad[i].FileName || e.LineNo != bad[i].LineNo || e.Pattern == "" {
+			t.Errorf("Error(%d): expected line %d and a pattern, got %s\n", code, bad[i].LineNo, e)
+		}
+	}
+	if len(r.Errors()) != len(codes) {
+		t.Errorf("Expected Errors() to have %d errors, got %d\n", len(codes), len(r.Errors()))
+	}
+
+	// The good route is still served.
+	arrived = 0
+	r.ServeHTTP(new(mockResponseWriter), newTestRequest("GET", "/ok/12"))
+	if arrived != 9701 {
+		t.Errorf("Expected 9701, got %d\n", arrived)
+	}
+
+	// An error in a matcher is kept on the route it is added to.
+	r2 := NewRouter()
+	r2.HandleFunc("/hm", createFx(9708)).Matcher(HostMatcher("{sub.example.com"))
+	r2.HandleFunc("/qm", createFx(9709)).Matcher(Or(QueryMatcher("a", "{a:(}"), HeaderMatcher("X-A", "1")))
+	if errs := r2.Errors(); len(errs) != 2 || errs[0].Code != 20040 || errs[1].Code != 20041 {
+		t.Errorf("Expected Error(20040) and Error(20041) from the matchers, got %v\n", errs)
+	}
+
+	// A matcher that is made and not added to a route is not an error for the next route.
+	r3 := NewRouter()
+	notHost := Not(HostMatcher("{sub.example.com"))
+	r3.HandleFunc("/a", createFx(9710)).MatcherFunc(SchemeMatcher("http").Match)
+	if errs := r3.Errors(); len(errs) != 0 {
+		t.Errorf("Expected no errors for a route without the bad matcher, got %v\n", errs)
+	}
+	r3.HandleFunc("/b", createFx(9711)).Matcher(notHost)
+	if errs := r3.Errors(); len(errs) != 1 || errs[0].Code != 20040 {
+		t.Errorf("Expected Error(20040) from the matcher in Not, got %v\n", errs)
+	}
+
+	if err = NewRouter().CompileRoutes(); err != nil {
+		t.Errorf("Expected no error for no routes, got %s\n", err)
+	}
+}
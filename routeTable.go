@@ -0,0 +1,229 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// RouteTable is everything that is built from the routes by a compile.  A table is
+// never changed after it is built.  The router holds a pointer to the current table
+// and each request loads it once, so a new table can be swapped in while requests are
+// being served.  Requests that are in flight finish on the table they started with.
+type RouteTable struct {
+	src         []*ARoute       // The routes this table was built from, as they were registered
+	routes      []*ARoute       // Copies of the routes, defaults are filled in on these
+	from        []*ARoute       // from[i] is the registered route that routes[i] was copied from
+	routeData   []RouteData     // Raw routes - expanded by method/host/port before the hash is built
+	Hash2Test   []int           // Hash of the URL segments to an index in LookupResults
+	nMatch      []UrlPat        // The T::T patterns, Index by Length ( NSl )
+	MaxSlash    int             // Maximum number of slashes found in any route
+	methods     []string        // Sorted list of the methods used by any route
+	noCase      bool            // Some routes are case-insensitive
+	strictSlash bool            // From StrictSlash, a trailing '/' is an empty last word
+	paramFirst  map[int]uint64  // By method code, bit n is set if a route with n words starts with a param, see isParamFirst
+	allHostPort []*hostTemplate // From HostPort_AllRoutes
+	conflicts   []RouteConflict // Routes that can not be reached, see findConflicts
+	errors      []*RouteError   // Problems with the routes, see Errors
+
+	LookupResults  []Collision2
+	nLookupResults int
+}
+
+func newRouteTable(routes []*ARoute) *RouteTable {
+	t := &RouteTable{
+		src:            append([]*ARoute(nil), routes...), // Later HandleFunc calls must not change this table
+		MaxSlash:       1,
+		nLookupResults: 1,
+	}
+	for _, v := range routes {
+		x := *v // A table in use is never changed, so compile a copy of each route.
+		t.routes = append(t.routes, &x)
+		t.from = append(t.from, v)
+		t.errors = append(t.errors, v.errors...)
+	}
+	fn, ln := LineFile(2)
+	t.LookupResults = append(t.LookupResults, Collision2{cType: Dummy, FileName: fn, LineNo: ln})
+	t.Hash2Test = make([]int, bitMask+1, bitMask+1)
+	t.nMatch = make([]UrlPat, MaxSlashInUrl, MaxSlashInUrl)
+	t.paramFirst = make(map[int]uint64)
+	return t
+}
+
+// Leave disabled routes out of the table so that the match falls through to the next
+// route, or if h is set then call h when a disabled route matches.
+func (t *RouteTable) disableRoutes(h http.HandlerFunc) {
+	routes, from := t.routes[:0], t.from[:0]
+	for i, v := range t.routes {
+		if v.disabled {
+			if h == nil {
+				continue
+			}
+			v.DHandlerFunc = func(w http.ResponseWriter, req *http.Request, ps Params) {
+				h(w, req)
+			}
+		}
+		routes = append(routes, v)
+		from = append(from, t.from[i])
+	}
+	t.routes, t.from = routes, from
+}
+
+// Record a route for method m with n words that starts with a param.  A route with a
+// catch-all matches URLs with n or more words.
+func (t *RouteTable) setParamFirst(m int, n int, segs []RouteSegment) {
+	hi := n
+	if segs[len(segs)-1].Kind == SegCatchAll {
+		hi = 63
+	}
+	for ; n <= hi && n < 64; n++ {
+		t.paramFirst[m] |= 1 << uint(n)
+	}
+}
+
+// For the early exit in SplitOnSlash3, true if a route for method m that starts with a
+// param could match a URL with the words in rest, from the '/' after the first word.
+// The first word can not be used to exit early for such a route.
+func (t *RouteTable) isParamFirst(m int, rest string, slash bool) bool {
+	bits := t.paramFirst[m]
+	if bits == 0 {
+		return false
+	}
+	if strings.Contains(rest, "//") || strings.Contains(rest, "/.") {
+		return true // The URL will be cleaned, so the number of words is not known yet
+	}
+	n := 1 + strings.Count(rest, "/")
+	if len(rest) > 1 && rest[len(rest)-1] == '/' && !slash && !t.strictSlash {
+		n-- // A trailing '/' is not a word
+	}
+	if n > 63 {
+		n = 63
+	}
+	return bits&(1<<uint(n)) != 0
+}
+
+// Build the list of methods that are used, used to find the Allow header.
+func (t *RouteTable) setMethods() {
+	seen := make(map[string]bool)
+	for _, v := range t.routeData {
+		if !seen[v.Method] {
+			seen[v.Method] = true
+			t.methods = append(t.methods, v.Method)
+		}
+	}
+	sort.Strings(t.methods)
+}
+
+// RouteTable returns the table that is currently in use, nil if the routes have
+// not been compiled yet.
+func (r *MuxRouter) RouteTable() *RouteTable {
+	t, _ := r.table.Load().(*RouteTable)
+	return t
+}
+
+// SwapRouteTable puts a table from BuildRouteTable into use.  New requests use it
+// as soon as this returns.  The table that was in use is kept for Rollback.
+// A router that is never served can be used to set up the new routes.
+//
+//	staging := NewRouter()
+//	staging.HandleFunc("/api/:id", getIt).Methods("GET")
+//	r.SwapRouteTable(r.BuildRouteTable(staging.ListRoutes()))
+func (r *MuxRouter) SwapRouteTable(t *RouteTable) {
+	r.compileLock.Lock()
+	defer r.compileLock.Unlock()
+	r.prevTable = r.RouteTable()
+	r.rejected = nil
+	r.routes = t.src
+	for _, v := range r.routes {
+		v.parent = r // Routes from a staging router, and their Subrouters, now change this router
+	}
+	r.resetNames()
+	r.HasBeenCompiled = true
+	atomic.StoreInt32(&r.dirty, 0)
+	r.table.Store(t)
+}
+
+// Rollback goes back to the table that was in use before the last SwapRouteTable.
+// Only one level is kept.  The table is restored as it was built, so routes that have
+// been disabled or removed since then are back until the next compile.
+func (r *MuxRouter) Rollback() error {
+	r.compileLock.Lock()
+	defer r.compileLock.Unlock()
+	if r.prevTable == nil {
+		return fmt.Errorf("gogomux: Rollback: no previous route table")
+	}
+	r.routes = r.prevTable.src
+	r.resetNames()
+	r.rejected = nil
+	r.table.Store(r.prevTable)
+	r.prevTable = nil
+	return nil
+}
+
+// RemoveRoute removes the route with the name set by Name() and compiles a new table.
+// If more than one route has the name then all of them are removed.
+func (r *MuxRouter) RemoveRoute(name string) error {
+	r.compileLock.Lock()
+	defer r.compileLock.Unlock()
+	routes := make([]*ARoute, 0, len(r.routes))
+	for _, v := range r.routes {
+		if v.DName != name {
+			routes = append(routes, v)
+		}
+	}
+	if len(routes) == len(r.routes) {
+		return fmt.Errorf("gogomux: RemoveRoute: no route named %q", name)
+	}
+	r.routes = routes
+	r.resetNames()
+	atomic.StoreInt32(&r.dirty, 1)
+	r.compileLocked()
+	return nil
+}
+
+// DisableRoute stops the named route from matching.  See RouteDisabled in MuxRouter
+// for what happens to requests that would have used it.
+func (r *MuxRouter) DisableRoute(name string) error {
+	return r.setDisabled("DisableRoute", name, true)
+}
+
+// EnableRoute puts a route that was disabled with DisableRoute back in use.
+func (r *MuxRouter) EnableRoute(name string) error {
+	return r.setDisabled("EnableRoute", name, false)
+}
+
+func (r *MuxRouter) setDisabled(fn, name string, d bool) error {
+	r.compileLock.Lock()
+	defer r.compileLock.Unlock()
+	found := false
+	for _, v := range r.routes {
+		if v.DName == name {
+			v.disabled = d
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("gogomux: %s: no route named %q", fn, name)
+	}
+	atomic.StoreInt32(&r.dirty, 1)
+	r.compileLocked()
+	return nil
+}
+
+// ServiceUnavailable replies to the request with an HTTP 503 error.  It can be used
+// for RouteDisabled in MuxRouter.
+func ServiceUnavailable(w http.ResponseWriter, req *http.Request) {
+	http.Error(w, "503 service unavailable", http.StatusServiceUnavailable)
+}
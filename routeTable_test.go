@@ -0,0 +1,190 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func Test_SwapRouteTable(t *testing.T) {
+	w := new(mockResponseWriter)
+	r := NewRouter()
+	r.HandleFunc("/v1/:id", createFx(7001)).Methods("GET")
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+	r.CompileRoutes()
+
+	if err := r.Rollback(); err == nil {
+		t.Errorf("Expected an error from Rollback with no previous table\n")
+	}
+
+	staging := NewRouter()
+	staging.HandleFunc("/v2/:id", createFx(7002)).Methods("GET")
+	r.SwapRouteTable(r.BuildRouteTable(staging.ListRoutes()))
+
+	r.ServeHTTP(w, newTestRequest("GET", "/v2/12"))
+	if arrived != 7002 {
+		t.Errorf("Expected 7002 after swap, got %d\n", arrived)
+	}
+	r.ServeHTTP(w, newTestRequest("GET", "/v1/12"))
+	if arrived != -1 {
+		t.Errorf("Expected not found for old route after swap, got %d\n", arrived)
+	}
+
+	if err := r.Rollback(); err != nil {
+		t.Errorf("Unexpected error from Rollback: %s\n", err)
+	}
+	r.ServeHTTP(w, newTestRequest("GET", "/v1/12"))
+	if arrived != 7001 {
+		t.Errorf("Expected 7001 after Rollback, got %d\n", arrived)
+	}
+	r.ServeHTTP(w, newTestRequest("GET", "/v2/12"))
+	if arrived != -1 {
+		t.Errorf("Expected not found for new route after Rollback, got %d\n", arrived)
+	}
+}
+
+// A request that is running when the table is swapped must finish on the table it started with.
+func Test_SwapRouteTableInFlight(t *testing.T) {
+	w := new(mockResponseWriter)
+	started := make(chan bool)
+	release := make(chan bool)
+	var got string
+
+	r := NewRouter()
+	r.HandleFunc("/slow/:id", func(w http.ResponseWriter, req *http.Request, ps Params) {
+		started <- true
+		<-release
+		got = ps.ByName("id")
+	}).Methods("GET")
+	r.CompileRoutes()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.ServeHTTP(w, newTestRequest("GET", "/slow/42"))
+	}()
+	<-started
+
+	old := r.RouteTable()
+	staging := NewRouter()
+	staging.HandleFunc("/fast", createFx(7003)).Methods("GET")
+	r.SwapRouteTable(r.BuildRouteTable(staging.ListRoutes()))
+	if r.RouteTable() == old {
+		t.Errorf("Expected a new table after swap\n")
+	}
+
+	close(release)
+	wg.Wait()
+	if got != "42" {
+		t.Errorf("Expected in flight request to get id 42, got ->%s<-\n", got)
+	}
+}
+
+// Routes from the staging router belong to r after the swap, a change to one is
+// compiled into r and a Subrouter adds its routes to r.
+func Test_SwapRouteTableEdit(t *testing.T) {
+	w := new(mockResponseWriter)
+	r := NewRouter()
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+	r.CompileRoutes()
+
+	staging := NewRouter()
+	route := staging.HandleFunc("/v2/:id", createFx(7004)).Methods("GET")
+	s := staging.PathPrefix("/api/").Subrouter()
+	s.HandleFunc("/users", createFx(7005)).Methods("GET")
+	r.SwapRouteTable(r.BuildRouteTable(staging.ListRoutes()))
+
+	route.Methods("POST").Name("v2")
+	r.ServeHTTP(w, newTestRequest("POST", "/v2/12"))
+	if arrived != 7004 {
+		t.Errorf("Expected 7004 for POST after the route was changed, got %d\n", arrived)
+	}
+	if u, err := r.Get("v2").URL("id", "12"); err != nil || u.String() != "/v2/12" {
+		t.Errorf("Expected the name set after the swap to be found, got %v %v\n", u, err)
+	}
+
+	s.HandleFunc("/groups", createFx(7006)).Methods("GET")
+	r.ServeHTTP(w, newTestRequest("GET", "/api/groups"))
+	if arrived != 7006 {
+		t.Errorf("Expected 7006 for a route added to the Subrouter after the swap, got %d\n", arrived)
+	}
+	if len(staging.ListRoutes()) != 3 {
+		t.Errorf("Expected the staging router to be unchanged, got %d routes\n", len(staging.ListRoutes()))
+	}
+}
+
+func Test_RuntimeRoutes(t *testing.T) {
+	w := new(mockResponseWriter)
+	r := NewRouter()
+	r.HandleFunc("/api/:id", createFx(7010)).Methods("GET").Name("byId")
+	r.HandleFunc("/api/special", createFx(7011)).Methods("GET").Name("special")
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	r.ServeHTTP(w, newTestRequest("GET", "/api/special"))
+	if arrived != 7011 {
+		t.Errorf("Expected 7011, got %d\n", arrived)
+	}
+
+	// Added after the first request has been served.
+	r.HandleFunc("/api/:id/more", createFx(7012)).Methods("GET").Name("more")
+	r.ServeHTTP(w, newTestRequest("GET", "/api/12/more"))
+	if arrived != 7012 {
+		t.Errorf("Expected 7012 for route added after serving, got %d\n", arrived)
+	}
+
+	// Disabled falls through to the next pattern.
+	if err := r.DisableRoute("special"); err != nil {
+		t.Errorf("Unexpected error: %s\n", err)
+	}
+	r.ServeHTTP(w, newTestRequest("GET", "/api/special"))
+	if arrived != 7010 {
+		t.Errorf("Expected disabled route to fall through to 7010, got %d\n", arrived)
+	}
+
+	// Disabled with a 503 response.
+	r.RouteDisabled = ServiceUnavailable
+	if err := r.DisableRoute("special"); err != nil {
+		t.Errorf("Unexpected error: %s\n", err)
+	}
+	arrived = 0
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newTestRequest("GET", "/api/special"))
+	if rec.Code != http.StatusServiceUnavailable || arrived != 0 {
+		t.Errorf("Expected 503 for disabled route, got %d arrived=%d\n", rec.Code, arrived)
+	}
+
+	if err := r.EnableRoute("special"); err != nil {
+		t.Errorf("Unexpected error: %s\n", err)
+	}
+	r.ServeHTTP(w, newTestRequest("GET", "/api/special"))
+	if arrived != 7011 {
+		t.Errorf("Expected 7011 after EnableRoute, got %d\n", arrived)
+	}
+
+	if err := r.RemoveRoute("more"); err != nil {
+		t.Errorf("Unexpected error: %s\n", err)
+	}
+	r.ServeHTTP(w, newTestRequest("GET", "/api/12/more"))
+	if arrived != -1 {
+		t.Errorf("Expected not found for removed route, got %d\n", arrived)
+	}
+
+	if err := r.RemoveRoute("more"); err == nil {
+		t.Errorf("Expected an error removing a route that is not there\n")
+	}
+	if err := r.DisableRoute("nope"); err == nil {
+		t.Errorf("Expected an error disabling a route that is not there\n")
+	}
+}
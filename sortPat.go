@@ -29,7 +29,7 @@ type multiSorterPat struct {
 }
 
 // Sort sorts the argument slice according to the less functions passed to OrderedBy.
-// var nMatch []UrlPat  // Index by Length ( NSl )
+// r.nMatch is a []UrlPat, Index by Length ( NSl )
 func (ms *multiSorterPat) Sort(the_data []UrlAPat) {
 	ms.the_data = the_data
 	sort.Sort(ms)
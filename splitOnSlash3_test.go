@@ -81,9 +81,10 @@ var testRuns_SplitOnSlash3 = []struct {
 
 func TestSplitOnSlash3(t *testing.T) {
 
+	ms := NewMatchState()
 	for k, test := range testRuns_SplitOnSlash3 {
-		htx.SplitOnSlash3(0, test.param, false)
-		s := arrFrom(htx.Slash[:], htx.NSl, htx.CurUrl, htx.Hash[:])
+		htx.SplitOnSlash3(ms, 0, test.param, false)
+		s := arrFrom(ms.Slash[:], ms.NSl, ms.CurUrl, ms.Hash[:])
 		// r.Hash[NSl-1] = h
 		// r.Slash[NSl] = ln
 		// r.NSl = NSl
@@ -91,8 +92,8 @@ func TestSplitOnSlash3(t *testing.T) {
 		if s != test.slash {
 			t.Errorf("Test %d - Url(%v) = ", k, test.param)
 		}
-		if htx.NSl != test.nsl {
-			t.Errorf("Test %d - Url(%v) NSl = %d, expected %d ", k, test.param, htx.NSl, test.nsl)
+		if ms.NSl != test.nsl {
+			t.Errorf("Test %d - Url(%v) NSl = %d, expected %d ", k, test.param, ms.NSl, test.nsl)
 		}
 	}
 }
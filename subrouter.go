@@ -0,0 +1,165 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import "strings"
+
+// SubRouter registers routes that inherit from a parent route.  The host, port,
+// schemes, headers, queries, methods, protocal, matcher functions and path prefix of
+// the parent are added to each route when the table is compiled, so the routes are
+// still in the one hash table and the lookup is as fast as any other route.
+//
+//	s := r.Host("{tenant}.example.com").PathPrefix("/api").Subrouter()
+//	s.HandleFunc("/users/:id", getUser)				// /api/users/:id on {tenant}.example.com
+//	s.HandleFunc("/users/:id", putUser).Methods("PUT")	// A method set on the route is used instead of the parent's
+type SubRouter struct {
+	group *ARoute
+}
+
+// Subrouter returns a SubRouter for this route.  The route is only used as a group, it
+// is not in the table unless it has a handler.
+func (r *ARoute) Subrouter() *SubRouter {
+	r.isGroup = true
+	r.changed()
+	return &SubRouter{group: r}
+}
+
+// NewRoute registers an empty route in the group.
+func (s *SubRouter) NewRoute() *ARoute {
+	fn, ln := LineFile(3)
+	return s.group.parent.newRoute(s.group, fn, ln) // The group's router, it changes in SwapRouteTable
+}
+
+// HandleFunc registers a new route in the group with a matcher for the URL path.
+func (s *SubRouter) HandleFunc(path string, f HandleFunc) *ARoute {
+	return s.NewRoute().HandleFunc(path, f)
+}
+
+// Path registers a new route in the group with a matcher for the URL path.
+func (s *SubRouter) Path(tpl string) *ARoute {
+	return s.NewRoute().Path(tpl)
+}
+
+// PathPrefix registers a new route in the group with a matcher for the URL path prefix.
+func (s *SubRouter) PathPrefix(p string) *ARoute {
+	return s.NewRoute().PathPrefix(p)
+}
+
+// Headers registers a new route in the group with a matcher for request header values.
+func (s *SubRouter) Headers(pairs ...string) *ARoute {
+	return s.NewRoute().Headers(pairs...)
+}
+
+// Host registers a new route in the group with a matcher for the URL host.
+func (s *SubRouter) Host(tpl string) *ARoute {
+	return s.NewRoute().Host(tpl)
+}
+
+// Port registers a new route in the group with a matcher for the port.
+func (s *SubRouter) Port(tpl string) *ARoute {
+	return s.NewRoute().Port(tpl)
+}
+
+// HostPort registers a new route in the group with a matcher for the host and port.
+func (s *SubRouter) HostPort(tpl string) *ARoute {
+	return s.NewRoute().HostPort(tpl)
+}
+
+// Methods registers a new route in the group with a matcher for HTTP methods.
+func (s *SubRouter) Methods(methods ...string) *ARoute {
+	return s.NewRoute().Methods(methods...)
+}
+
+// Queries registers a new route in the group with a matcher for URL query values.
+func (s *SubRouter) Queries(q ...string) *ARoute {
+	return s.NewRoute().Queries(q...)
+}
+
+// Schemes registers a new route in the group with a matcher for URL schemes.
+func (s *SubRouter) Schemes(schemes ...string) *ARoute {
+	return s.NewRoute().Schemes(schemes...)
+}
+
+// MatcherFunc registers a new route in the group with a custom matcher function.
+func (s *SubRouter) MatcherFunc(f MatcherFunc) *ARoute {
+	return s.NewRoute().MatcherFunc(f)
+}
+
+// Matcher registers a new route in the group with a matcher.
+func (s *SubRouter) Matcher(m Matcher) *ARoute {
+	return s.NewRoute().Matcher(m)
+}
+
+// Add what each route inherits from its group.  Groups with no handler are left out
+// of the table.
+func (t *RouteTable) inheritGroups() {
+	routes, from := t.routes[:0], t.from[:0]
+	for i, v := range t.routes {
+		if v.group != nil {
+			inheritFrom(v, v.group)
+		}
+		if v.isGroup && v.DHandlerFunc == nil {
+			continue
+		}
+		routes = append(routes, v)
+		from = append(from, t.from[i])
+	}
+	t.routes, t.from = routes, from
+}
+
+// Add the settings from the group g to v.  v is a copy, the slices are not shared with g.
+// Settings that have one value (host, methods ...) are only used if v does not set them,
+// lists of pairs and functions from g are checked first.
+func inheritFrom(v *ARoute, g *ARoute) {
+	if g.group != nil {
+		x := *g
+		inheritFrom(&x, g.group)
+		g = &x
+	}
+	v.DPathPrefix = joinPath(joinPath(g.DPathPrefix, g.DPath), v.DPathPrefix)
+	if v.DHost == "" {
+		v.DHost = g.DHost
+	}
+	if v.DPort == "" {
+		v.DPort = g.DPort
+	}
+	if v.DHostPort == "" {
+		v.DHostPort = g.DHostPort
+	}
+	if len(v.DMethods) == 0 {
+		v.DMethods = append([]string(nil), g.DMethods...)
+	}
+	if len(v.DSchemes) == 0 {
+		v.DSchemes = append([]string(nil), g.DSchemes...)
+	}
+	if IsMapStringBoolEmpty(v.DProtocal) {
+		v.DProtocal = g.DProtocal
+	}
+	v.DHeaders = append(append([]string(nil), g.DHeaders...), v.DHeaders...)
+	v.DQueries = append(append([]string(nil), g.DQueries...), v.DQueries...)
+	v.DMatchers = append(append([]MatcherFunc(nil), g.DMatchers...), v.DMatchers...)
+	v.DNoCase = v.DNoCase || g.DNoCase
+	v.disabled = v.disabled || g.disabled
+}
+
+// Join two parts of a path with one '/' between them, /api/ and /static/ is /api/static/.
+// An empty part is left out.
+func joinPath(a, b string) string {
+	switch {
+	case a == "" || b == "":
+		return a + b
+	case strings.HasSuffix(a, "/") && strings.HasPrefix(b, "/"):
+		return a + b[1:]
+	case !strings.HasSuffix(a, "/") && !strings.HasPrefix(b, "/"):
+		return a + "/" + b
+	}
+	return a + b
+}
@@ -0,0 +1,125 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_Subrouter(t *testing.T) {
+	w := new(mockResponseWriter)
+	r := NewRouter()
+	var got Params
+	save := func(n int) HandleFunc {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			arrived = n
+			got = ps.Copy()
+		}
+	}
+	api := r.Host("{tenant}.example.com").PathPrefix("/api").Headers("X-Key", "").Methods("GET", "POST").Subrouter()
+	api.HandleFunc("/users/:id", save(9301))
+	api.HandleFunc("/users/:id", save(9302)).Methods("DELETE")
+	v2 := api.PathPrefix("/v2").Queries("fmt", "{fmt}").Subrouter()
+	v2.HandleFunc("/items/:id", save(9303))
+	r.HandleFunc("/api/users/:id", save(9304)).Methods("GET")
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	tests := []struct {
+		method string
+		url    string
+		host   string
+		key    bool
+		expect int
+		name   string
+		value  string
+	}{
+		{"GET", "/api/users/12", "acme.example.com", true, 9301, "tenant", "acme"},
+		{"POST", "/api/users/12", "acme.example.com", true, 9301, "id", "12"},
+		{"DELETE", "/api/users/12", "acme.example.com", true, 9302, "id", "12"},
+		{"GET", "/api/users/12", "acme.example.com", false, 9304, "id", "12"},
+		{"GET", "/api/users/12", "localhost:8080", true, 9304, "id", "12"},
+		{"GET", "/api/v2/items/7?fmt=json", "acme.example.com", true, 9303, "fmt", "json"},
+		{"GET", "/api/v2/items/7", "acme.example.com", true, -1, "", ""},
+		{"GET", "/api", "acme.example.com", true, -1, "", ""},
+	}
+	for i, test := range tests {
+		arrived = 0
+		req := newTestRequest(test.method, test.url)
+		req.Host = test.host
+		if test.key {
+			req.Header.Set("X-Key", "k")
+		}
+		r.ServeHTTP(w, req)
+		if arrived != test.expect {
+			t.Errorf("Test %d: %s %s expected %d, got %d\n", i, test.method, test.url, test.expect, arrived)
+			continue
+		}
+		if test.name != "" && got.ByName(test.name) != test.value {
+			t.Errorf("Test %d: expected %s=%s, got %s\n", i, test.name, test.value, got.DumpParam())
+		}
+	}
+
+	// The group is read when the table is compiled, a change to it is used by its routes.
+	api.group.Methods("PUT")
+	arrived = 0
+	req := newTestRequest("PUT", "/api/users/12")
+	req.Host = "acme.example.com"
+	req.Header.Set("X-Key", "k")
+	r.ServeHTTP(w, req)
+	if arrived != 9301 {
+		t.Errorf("Expected 9301 for PUT after the group changed, got %d\n", arrived)
+	}
+}
+
+// The prefixes of nested groups are joined with one '/'.
+func Test_SubrouterNestedPrefix(t *testing.T) {
+	w := new(mockResponseWriter)
+	r := NewRouter()
+	var got Params
+	save := func(n int) HandleFunc {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			arrived = n
+			got = ps.Copy()
+		}
+	}
+	api := r.PathPrefix("/api/").Subrouter()
+	api.PathPrefix("/static/").HandlerFunc(save(9311))
+	api.PathPrefix("/v1").Subrouter().HandleFunc("/users/:id", save(9312)).Name("v1user")
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	tests := []struct {
+		url    string
+		expect int
+		name   string
+		value  string
+	}{
+		{"/api/static/app.js", 9311, PathPrefixParam, "app.js"},
+		{"/api/static/css/a.css", 9311, PathPrefixParam, "css/a.css"},
+		{"/api/v1/users/12", 9312, "id", "12"},
+		{"/api/users/12", -1, "", ""},
+	}
+	for i, test := range tests {
+		arrived = 0
+		got = Params{}
+		r.ServeHTTP(w, newTestRequest("GET", test.url))
+		if arrived != test.expect {
+			t.Errorf("Test %d: %s expected %d, got %d\n", i, test.url, test.expect, arrived)
+			continue
+		}
+		if test.name != "" && got.ByName(test.name) != test.value {
+			t.Errorf("Test %d: %s expected %s=%s, got %s\n", i, test.url, test.name, test.value, got.DumpParam())
+		}
+	}
+	if u, err := r.Get("v1user").URL("id", "12"); err != nil || u.String() != "/api/v1/users/12" {
+		t.Errorf("Expected /api/v1/users/12, got %v %v\n", u, err)
+	}
+}
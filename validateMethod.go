@@ -10,12 +10,6 @@ package gogomux
 // /Users/corwin/Projects/gogo2
 //
 
-import (
-	"fmt"
-
-	debug "github.com/pschlump/godebug"
-)
-
 // Table of valid methods,  If other http-methods are created or used they should be added to this list.
 var validMethod map[string]bool
 
@@ -46,10 +40,11 @@ func init() {
 	validProtocal["HTTP/2.0"] = true
 }
 
-func checkInBoolMap(mm []string, cmpTo map[string]bool, em string) bool {
+// Returns false and the first value that is not in cmpTo.  The error is kept on the route.
+func checkInBoolMap(r *ARoute, mm []string, cmpTo map[string]bool, em string) bool {
 	for _, v := range mm {
 		if b, ok := cmpTo[v]; !ok || !b {
-			fmt.Printf("Error(20000): %s %s is invalid.  Called From: %s\n", em, v, debug.LF(4))
+			r.addError(20000, em+" is invalid", v)
 			return false
 		}
 	}
@@ -57,16 +52,16 @@ func checkInBoolMap(mm []string, cmpTo map[string]bool, em string) bool {
 }
 
 // Check for GET, PUT etc.
-func checkMethods(methods []string) bool {
-	return checkInBoolMap(methods, validMethod, "Method")
+func checkMethods(r *ARoute, methods []string) bool {
+	return checkInBoolMap(r, methods, validMethod, "Method")
 }
 
 // Check for https / http - valid schemes
-func checkScheme(s []string) bool {
-	return checkInBoolMap(s, validScheme, "Scheme")
+func checkScheme(r *ARoute, s []string) bool {
+	return checkInBoolMap(r, s, validScheme, "Scheme")
 }
 
 // Check for HTTP/1.0 etc.
-func checkProtocal(s []string) bool {
-	return checkInBoolMap(s, validProtocal, "Protocal")
+func checkProtocal(r *ARoute, s []string) bool {
+	return checkInBoolMap(r, s, validProtocal, "Protocal")
 }
@@ -0,0 +1,102 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// valueMatch is one name/value pair from Headers() or Queries() after compile.  The value
+// can be
+//
+//	""              the name has to be present, any value
+//	"text"          one of the values has to be "text"
+//	"{id}"          the name has to be present, the value is put in the Params as "id"
+//	"{id:[0-9]+}"   one of the values has to match the regular expression, it is put in the Params as "id"
+type valueMatch struct {
+	name    string         // Header (canonical) or query name
+	value   string         // Exact value, if re == nil and capture == ""
+	re      *regexp.Regexp // nil if not {name:re}
+	capture string         // Param name for {name} and {name:re}
+	present bool           // Only check that name is present
+}
+
+// Compile the pairs from Headers() or Queries().  On error the pair is compared as text,
+// the first error is returned.
+func compileValueMatch(pairs []string, header bool, FileName string, LineNo int) (rv []valueMatch, e *RouteError) {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		vm := valueMatch{name: pairs[i], value: pairs[i+1]}
+		if header {
+			vm.name = http.CanonicalHeaderKey(vm.name)
+		}
+		v := vm.value
+		if v == "" {
+			vm.present = true
+		} else if len(v) > 2 && v[0] == '{' && v[len(v)-1] == '}' {
+			name, re := v[1:len(v)-1], ""
+			if colon := strings.IndexByte(name, ':'); colon >= 0 {
+				name, re = name[:colon], name[colon+1:]
+			}
+			cre, err := regexp.Compile("^(?:" + strings.TrimSuffix(strings.TrimPrefix(re, "^"), "$") + ")$")
+			if name == "" || err != nil {
+				if e == nil {
+					msg := fmt.Sprintf("invalid value %s for %s, the name is missing", v, vm.name)
+					if err != nil {
+						msg = fmt.Sprintf("invalid value %s for %s, %s", v, vm.name, err)
+					}
+					e = newRouteError(20041, msg, v, FileName, LineNo)
+				}
+			} else {
+				vm.capture = name
+				vm.present = re == ""
+				if !vm.present {
+					vm.re = cre
+				}
+			}
+		}
+		rv = append(rv, vm)
+	}
+	return
+}
+
+// Check all of the pairs against the values, captured values are added to ps.
+func matchValues(vms []valueMatch, values map[string][]string, ft FromType, pt ParamType, ps *Params) bool {
+	for _, vm := range vms {
+		vv, ok := values[vm.name]
+		if !ok {
+			return false
+		}
+		found := ""
+		if vm.present {
+			if len(vv) > 0 {
+				found = vv[0]
+			}
+		} else {
+			ok = false
+			for _, x := range vv {
+				if (vm.re != nil && vm.re.MatchString(x)) || (vm.re == nil && x == vm.value) {
+					ok, found = true, x
+					break
+				}
+			}
+			if !ok {
+				return false
+			}
+		}
+		if vm.capture != "" {
+			AddValueToParams(vm.capture, found, pt, ft, ps)
+		}
+	}
+	return true
+}
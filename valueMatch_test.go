@@ -0,0 +1,83 @@
+package gogomux
+
+//
+// Go Go Mux - Go Fast Mux / Router for HTTP requests
+//
+// (C) Philip Schlump, 2013-2015.
+// Version: 0.5.4
+// BuildNo: 810
+//
+// /Users/corwin/Projects/go-lib/gogomux
+//
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_QueryHeaderMatch(t *testing.T) {
+	w := new(mockResponseWriter)
+	r := NewRouter()
+	var got Params
+	save := func(n int) HandleFunc {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			arrived = n
+			got = ps.Copy()
+		}
+	}
+	r.HandleFunc("/q", save(9001)).Methods("GET").Queries("id", "{id:[0-9]+}")
+	r.HandleFunc("/q", save(9002)).Methods("GET").Queries("name", "{name}")
+	r.HandleFunc("/q", save(9003)).Methods("GET").Queries("debug", "")
+	r.HandleFunc("/h", save(9004)).Methods("GET").Headers("X-Version", "{ver:v[0-9]}")
+	r.HandleFunc("/h", save(9005)).Methods("GET").Headers("X-Token", "")
+	r.NotFound = func(w http.ResponseWriter, req *http.Request) { arrived = -1 }
+
+	tests := []struct {
+		url    string
+		header string
+		value  string
+		expect int
+		name   string
+		param  string
+	}{
+		{"/q?id=42", "", "", 9001, "id", "42"},
+		{"/q?id=abc&name=bob", "", "", 9002, "name", "bob"},
+		{"/q?debug", "", "", 9003, "", ""},
+		{"/q?other=1", "", "", -1, "", ""},
+		{"/h", "X-Version", "v2", 9004, "ver", "v2"},
+		{"/h", "X-Version", "v22", -1, "", ""},
+		{"/h", "X-Token", "", 9005, "", ""},
+	}
+	for i, test := range tests {
+		arrived = 0
+		req := newTestRequest("GET", test.url)
+		if test.header != "" {
+			req.Header[test.header] = []string{test.value}
+		}
+		r.ServeHTTP(w, req)
+		if arrived != test.expect {
+			t.Errorf("Test %d: %s expected %d, got %d\n", i, test.url, test.expect, arrived)
+			continue
+		}
+		if test.name != "" && got.ByName(test.name) != test.param {
+			t.Errorf("Test %d: expected %s=%s, got %s\n", i, test.name, test.param, got.DumpParam())
+		}
+	}
+}
+
+func Test_CompileValueMatch(t *testing.T) {
+	vm, e := compileValueMatch([]string{"a", "{x:[}", "b", "{y:^[a-z]+$}"}, false, "", 0)
+	if e == nil || e.Code != 20041 || e.Pattern != "{x:[}" || !strings.HasPrefix(e.Msg, "invalid value {x:[} for a, error parsing regexp") {
+		t.Errorf("Expected Error(20041) for {x:[}, got %v\n", e)
+	}
+	if _, e = compileValueMatch([]string{"a", "{:[0-9]+}"}, false, "", 0); e == nil || e.Msg != "invalid value {:[0-9]+} for a, the name is missing" {
+		t.Errorf("Expected Error(20041) for a missing name, got %v\n", e)
+	}
+	if vm[0].re != nil || vm[0].capture != "" {
+		t.Errorf("Expected a bad regular expression to be compared as text\n")
+	}
+	if vm[1].re == nil || !vm[1].re.MatchString("abc") || vm[1].re.MatchString("abc1") {
+		t.Errorf("Expected an anchored regular expression\n")
+	}
+}